@@ -4,13 +4,20 @@ import (
 	"Lesson13/internal/documentstore"
 	"Lesson13/internal/utils"
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"os"
+	"strings"
+	"time"
 )
 
+// sweepInterval - як часто видаляються прострочені документи (див. CollectionConfig.TTL)
+const sweepInterval = time.Minute
+
 func handleConnection(conn net.Conn, store *documentstore.Store) {
 	defer conn.Close()
 	reader := bufio.NewReader(conn)
@@ -72,7 +79,10 @@ func processCommand(command utils.Command, store *documentstore.Store) utils.Res
 
 	case "put_document":
 		var payload utils.PutDocumentPayload
-		if err := json.Unmarshal([]byte(command.Payload.(string)), &payload); err != nil {
+		// UseNumber зберігає точність цілих чисел більших за 2^53
+		decoder := json.NewDecoder(strings.NewReader(command.Payload.(string)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: "Невалідний payload для put_document"}}
 		}
 		collection, err := store.GetCollection(payload.Collection)
@@ -83,22 +93,62 @@ func processCommand(command utils.Command, store *documentstore.Store) utils.Res
 		if err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
 		}
-		err = collection.Put(*doc)
+		var key string
+		var version uint64
+		if payload.Version != nil {
+			key, version, err = collection.PutIfVersion(*doc, *payload.Version)
+		} else {
+			key, version, err = collection.Put(*doc)
+		}
+		if err != nil {
+			return errorResponse(err)
+		}
+		return utils.Response{Status: "ok", Result: &utils.PutDocumentResult{Key: key, Version: version, Message: fmt.Sprintf("Документ '%s' додано/оновлено в колекції '%s'", key, payload.Collection)}}
+
+	case "insert_document", "replace_document":
+		var payload utils.PutDocumentPayload
+		decoder := json.NewDecoder(strings.NewReader(command.Payload.(string)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err != nil {
+			return utils.Response{Status: "error", Error: &utils.Error{Message: fmt.Sprintf("Невалідний payload для %s", command.Command)}}
+		}
+		// Умовний запис за версією - лише через put_document
+		if payload.Version != nil {
+			return utils.Response{Status: "error", Error: &utils.Error{Code: utils.ErrorCodeInvalidPayload, Message: fmt.Sprintf("%s не підтримує version, використайте put_document", command.Command)}}
+		}
+		collection, err := store.GetCollection(payload.Collection)
+		if err != nil {
+			return utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
+		}
+		doc, err := documentstore.MarshalDocument(payload.Document)
 		if err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
 		}
-		return utils.Response{Status: "ok", Result: &utils.GenericResult{Message: fmt.Sprintf("Документ додано/оновлено в колекції '%s'", payload.Collection)}}
+		var key, action string
+		if command.Command == "insert_document" {
+			key, err = collection.Insert(*doc)
+			action = "додано до"
+		} else {
+			key, err = collection.Replace(*doc)
+			action = "замінено в"
+		}
+		if err != nil {
+			return errorResponse(err)
+		}
+		return utils.Response{Status: "ok", Result: &utils.PutDocumentResult{Key: key, Message: fmt.Sprintf("Документ '%s' %s колекції '%s'", key, action, payload.Collection)}}
 
 	case "get_document":
 		var payload utils.GetDeleteDocumentPayload
-		if err := json.Unmarshal([]byte(command.Payload.(string)), &payload); err != nil {
+		decoder := json.NewDecoder(strings.NewReader(command.Payload.(string)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: "Невалідний payload для get_document"}}
 		}
 		collection, err := store.GetCollection(payload.Collection)
 		if err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
 		}
-		docPtr, err := collection.Get(payload.Key)
+		docPtr, err := collection.Get(payload.KeyValues()...)
 		if err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
 		}
@@ -106,22 +156,49 @@ func processCommand(command utils.Command, store *documentstore.Store) utils.Res
 		if err := documentstore.UnmarshalDocument(docPtr, &result); err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
 		}
-		return utils.Response{Status: "ok", Result: &utils.GetDocumentResult{Document: result}}
+		return utils.Response{Status: "ok", Result: &utils.GetDocumentResult{Document: result, Version: docPtr.Version}}
 
 	case "delete_document":
 		var payload utils.GetDeleteDocumentPayload
-		if err := json.Unmarshal([]byte(command.Payload.(string)), &payload); err != nil {
+		decoder := json.NewDecoder(strings.NewReader(command.Payload.(string)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: "Невалідний payload для delete_document"}}
 		}
 		collection, err := store.GetCollection(payload.Collection)
 		if err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
 		}
-		err = collection.Delete(payload.Key)
+		if payload.Version != nil {
+			err = collection.DeleteIfVersion(*payload.Version, payload.KeyValues()...)
+		} else {
+			err = collection.Delete(payload.KeyValues()...)
+		}
+		if err != nil {
+			return errorResponse(err)
+		}
+		return utils.Response{Status: "ok", Result: &utils.GenericResult{Message: fmt.Sprintf("Документ з ключем %v видалено з колекції '%s'", payload.KeyValues(), payload.Collection)}}
+
+	case "update_document":
+		var payload utils.UpdateDocumentPayload
+		decoder := json.NewDecoder(strings.NewReader(command.Payload.(string)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err != nil {
+			return utils.Response{Status: "error", Error: &utils.Error{Message: "Невалідний payload для update_document"}}
+		}
+		collection, err := store.GetCollection(payload.Collection)
 		if err != nil {
 			return utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
 		}
-		return utils.Response{Status: "ok", Result: &utils.GenericResult{Message: fmt.Sprintf("Документ з ключем '%s' видалено з колекції '%s'", payload.Key, payload.Collection)}}
+		docPtr, err := collection.Update(payload.KeyValues(), payload.Update)
+		if err != nil {
+			return errorResponse(err)
+		}
+		var result map[string]interface{}
+		if err := documentstore.UnmarshalDocument(docPtr, &result); err != nil {
+			return utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
+		}
+		return utils.Response{Status: "ok", Result: &utils.UpdateDocumentResult{Document: result, Version: docPtr.Version}}
 
 	case "list_documents":
 		var payload utils.CollectionNamePayload
@@ -149,6 +226,21 @@ func processCommand(command utils.Command, store *documentstore.Store) utils.Res
 	}
 }
 
+// errorResponse формує відповідь з помилкою; конфлікт версій і наявність/відсутність
+// документа позначаються кодом, щоб клієнт міг відреагувати без розбору повідомлення
+func errorResponse(err error) utils.Response {
+	response := utils.Response{Status: "error", Error: &utils.Error{Message: err.Error()}}
+	switch {
+	case errors.Is(err, documentstore.ErrVersionConflict):
+		response.Error.Code = utils.ErrorCodeVersionConflict
+	case errors.Is(err, documentstore.ErrDocumentAlreadyExists):
+		response.Error.Code = utils.ErrorCodeAlreadyExists
+	case errors.Is(err, documentstore.ErrDocumentNotFound):
+		response.Error.Code = utils.ErrorCodeNotFound
+	}
+	return response
+}
+
 func main() {
 	listener, err := net.Listen("tcp", ":8080")
 	if err != nil {
@@ -158,6 +250,9 @@ func main() {
 	defer listener.Close()
 
 	store := documentstore.NewStore()
+	if err := store.StartSweeper(context.Background(), sweepInterval); err != nil {
+		log.Fatalf("Не вдалося запустити фонове видалення: %v", err)
+	}
 	log.Println("Сервер запущено та слухає на :8080")
 
 	for {
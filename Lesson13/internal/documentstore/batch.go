@@ -0,0 +1,328 @@
+package documentstore
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrBatchAborted = errors.New("batch aborted: another item failed")
+
+// BatchMode визначає, що робити, якщо частина елементів пакета не пройшла перевірку
+type BatchMode string
+
+const (
+	BatchAllOrNothing BatchMode = "all_or_nothing" // помилка в будь-якому елементі скасовує весь пакет
+	BatchBestEffort   BatchMode = "best_effort"    // застосовуються всі елементи, що пройшли перевірку
+)
+
+// BatchResult - результат для одного елемента пакета (у тому ж порядку, що й вхідні дані)
+type BatchResult struct {
+	Key     string
+	Version uint64 // нова версія для PutMany
+	Err     error  // ErrBatchAborted, якщо елемент коректний, але пакет скасовано
+}
+
+// PutMany записує пакет документів. Спочатку перевіряються всі документи
+// (ключ, схема, унікальні індекси, зокрема між документами пакета), потім
+// прийняті записуються під одним блокуванням, а кожен індекс оновлюється
+// одним злиттям. Якщо ключ повторюється в пакеті, зберігається останній документ.
+//
+// У режимі BatchAllOrNothing помилка будь-якого елемента скасовує весь пакет і
+// повертається разом з результатами; у BatchBestEffort помилки є лише в результатах.
+func (c *Collection) PutMany(docs []Document, mode BatchMode) ([]BatchResult, error) {
+	if c.config == nil {
+		return nil, errors.New("collection config is not initialized")
+	}
+	if err := mode.check(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.unlockAndRunHooks()
+	if c.documents == nil {
+		c.documents = make(map[string]Document)
+	}
+
+	results := make([]BatchResult, len(docs))
+	prepared := make([]Document, len(docs))
+	claims := newUniqueClaims(c)
+	failed := 0
+	for i, doc := range docs {
+		key, doc, err := c.prepareBatchPut(doc, claims)
+		results[i] = BatchResult{Key: key, Err: err}
+		prepared[i] = doc
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 && mode == BatchAllOrNothing {
+		return abortBatch(results)
+	}
+
+	// Запис документів; до індексів потрапляє лише останній документ для кожного ключа,
+	// у порядку останнього запису - так само, як при послідовних Put
+	final := make(map[string]Document, len(docs))
+	last := make(map[string]int, len(docs))
+	for i := range docs {
+		if results[i].Err != nil {
+			continue
+		}
+		key := results[i].Key
+		existing, exists := c.liveLocked(key)
+		c.revision = max(c.revision, existing.Version) + 1
+		prepared[i].Version = c.revision
+		results[i].Version = c.revision
+		c.documents[key] = prepared[i]
+		c.trackLocked(key, prepared[i])
+		final[key] = prepared[i]
+		last[key] = i
+		c.publishLocked(putEvent(ChangePut, key, existing, exists, prepared[i]))
+	}
+	order := make([]string, 0, len(final))
+	for i := range docs {
+		if results[i].Err == nil && last[results[i].Key] == i {
+			order = append(order, results[i].Key)
+		}
+	}
+	c.mergeIndexes(order, final)
+	c.evictLocked("")
+
+	slog.Debug("PutMany: batch applied", slog.Int("applied", len(docs)-failed), slog.Int("failed", failed))
+	return results, nil
+}
+
+// prepareBatchPut перевіряє документ пакета й резервує його значення в унікальних індексах
+func (c *Collection) prepareBatchPut(doc Document, claims *uniqueClaims) (string, Document, error) {
+	doc, err := c.assignKey(doc)
+	if err != nil {
+		return "", doc, err
+	}
+	if err := c.validate(doc); err != nil {
+		return "", doc, err
+	}
+	key, err := c.documentKey(doc)
+	if err != nil {
+		return "", doc, err
+	}
+	if doc, err = c.beforePutLocked(ChangePut, key, doc); err != nil {
+		return key, doc, err
+	}
+	if doc.ExpiresAt, err = c.expiresAt(doc, timeNow()); err != nil {
+		return key, doc, err
+	}
+	if err := c.checkSizeLocked(doc); err != nil {
+		return key, doc, err
+	}
+	if err := claims.claim(key, doc); err != nil {
+		return key, doc, err
+	}
+	return key, doc, nil
+}
+
+// DeleteMany видаляє пакет документів. Кожен елемент keys - значення ключа
+// (для простого ключа) або []any зі значеннями компонентів складеного ключа.
+// Режими та результати - як у PutMany.
+func (c *Collection) DeleteMany(keys []any, mode BatchMode) ([]BatchResult, error) {
+	if err := mode.check(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.unlockAndRunHooks()
+
+	results := make([]BatchResult, len(keys))
+	claimed := make(map[string]bool, len(keys))
+	failed := 0
+	for i, k := range keys {
+		values, ok := k.([]any)
+		if !ok {
+			values = []any{k}
+		}
+		key, err := c.lookupKey(values)
+		if err == nil {
+			// Повторний ключ у пакеті видаляє вже видалений документ
+			if _, exists := c.liveLocked(key); !exists || claimed[key] {
+				err = ErrDocumentNotFound
+			} else {
+				err = c.beforeDeleteLocked(key)
+			}
+		}
+		results[i] = BatchResult{Key: key, Err: err}
+		if err != nil {
+			failed++
+		} else {
+			claimed[key] = true
+		}
+	}
+	if failed > 0 && mode == BatchAllOrNothing {
+		return abortBatch(results)
+	}
+
+	removed := make(map[string]Document)
+	for _, r := range results {
+		if r.Err == nil {
+			c.publishLocked(deleteEvent(ChangeDelete, r.Key, c.documents[r.Key]))
+			removed[r.Key] = Document{}
+			delete(c.documents, r.Key)
+			c.untrackLocked(r.Key)
+		}
+	}
+	c.mergeIndexes(nil, removed)
+
+	slog.Debug("DeleteMany: batch applied", slog.Int("applied", len(removed)), slog.Int("failed", failed))
+	return results, nil
+}
+
+func (m BatchMode) check() error {
+	switch m {
+	case BatchAllOrNothing, BatchBestEffort:
+		return nil
+	}
+	return fmt.Errorf("unknown batch mode '%s'", m)
+}
+
+// abortBatch позначає коректні елементи скасованого пакета та повертає першу помилку
+func abortBatch(results []BatchResult) ([]BatchResult, error) {
+	var first error
+	for i := range results {
+		switch {
+		case results[i].Err == nil:
+			results[i].Err = ErrBatchAborted
+		case first == nil:
+			first = fmt.Errorf("item %d: %w", i, results[i].Err)
+		}
+	}
+	return results, first
+}
+
+// mergeIndexes оновлює кожен індекс одним проходом: прибирає записи для ключів
+// з changed і вливає відсортовані записи для документів з order.
+// Ключі з changed, яких немає в order, лише видаляються.
+func (c *Collection) mergeIndexes(order []string, changed map[string]Document) {
+	for _, index := range c.indexes {
+		var added []indexedEntry
+		for _, key := range order {
+			doc := changed[key]
+			if values, ok := index.values(doc); ok {
+				added = append(added, indexedEntry{Key: key, Document: doc, values: values})
+			}
+		}
+		sort.SliceStable(added, func(i, j int) bool {
+			return compareIndexKeys(added[i].values, added[j].values) < 0
+		})
+
+		merged := make([]indexedEntry, 0, len(index.Sorted)+len(added))
+		j := 0
+		for _, e := range index.Sorted {
+			if _, ok := changed[e.Key]; ok {
+				continue
+			}
+			// Нові записи стають після наявних з тим самим значенням, як в updateIndexes
+			for j < len(added) && compareIndexKeys(added[j].values, e.values) < 0 {
+				merged = append(merged, added[j])
+				j++
+			}
+			merged = append(merged, e)
+		}
+		index.Sorted = append(merged, added[j:]...)
+	}
+}
+
+// uniqueClaims перевіряє унікальні індекси для пакета: значення документа
+// не повинно збігатися ні з наявним документом з іншим ключем (якщо той
+// не перезаписується пакетом), ні з іншим документом пакета.
+type uniqueClaims struct {
+	c        *Collection
+	replaced map[string]bool              // ключі наявних документів, перезаписаних пакетом
+	claimed  map[string]map[string]string // індекс -> значення -> ключ документа пакета
+	byKey    map[string][]uniqueClaim     // ключ документа пакета -> його зарезервовані значення
+}
+
+type uniqueClaim struct {
+	index string
+	value string
+}
+
+func newUniqueClaims(c *Collection) *uniqueClaims {
+	return &uniqueClaims{
+		c:        c,
+		replaced: make(map[string]bool),
+		claimed:  make(map[string]map[string]string),
+		byKey:    make(map[string][]uniqueClaim),
+	}
+}
+
+func (u *uniqueClaims) claim(key string, doc Document) error {
+	var toClaim []uniqueClaim
+	now := timeNow()
+	for name, index := range u.c.indexes {
+		if !index.Unique {
+			continue
+		}
+		values, ok := index.values(doc)
+		if !ok || allNull(values) {
+			continue
+		}
+		value := indexValuesKey(values)
+		if owner, ok := u.claimed[name][value]; ok && owner != key {
+			return fmt.Errorf("%w: index '%s', document '%s' in the same batch", ErrUniqueViolation, name, owner)
+		}
+		pos := sort.Search(len(index.Sorted), func(i int) bool {
+			return compareIndexKeys(index.Sorted[i].values, values) >= 0
+		})
+		for i := pos; i < len(index.Sorted) && compareIndexKeys(index.Sorted[i].values, values) == 0; i++ {
+			if owner := index.Sorted[i].Key; owner != key && !u.replaced[owner] && !index.Sorted[i].Document.expired(now) {
+				return fmt.Errorf("%w: index '%s', existing document '%s'", ErrUniqueViolation, name, owner)
+			}
+		}
+		toClaim = append(toClaim, uniqueClaim{index: name, value: value})
+	}
+
+	// Документ прийнято. Попередній документ пакета з тим самим ключем буде
+	// перезаписано, тож його значення звільняються - як при послідовних Put.
+	for _, p := range u.byKey[key] {
+		if u.claimed[p.index][p.value] == key {
+			delete(u.claimed[p.index], p.value)
+		}
+	}
+	u.byKey[key] = toClaim
+	for _, p := range toClaim {
+		if u.claimed[p.index] == nil {
+			u.claimed[p.index] = make(map[string]string)
+		}
+		u.claimed[p.index][p.value] = key
+	}
+	if _, exists := u.c.documents[key]; exists {
+		u.replaced[key] = true
+	}
+	return nil
+}
+
+// indexValuesKey - рядкове представлення ключа індексу; однакове для значень,
+// які compareIndexValues вважає рівними (наприклад, 1 і 1.0)
+func indexValuesKey(values []DocumentField) string {
+	var b strings.Builder
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(indexTypeRank(v.Type)))
+		switch v.Type {
+		case DocumentFieldTypeString:
+			b.WriteString(strconv.Quote(v.Value.(string)))
+		case DocumentFieldTypeNumber, DocumentFieldTypeInteger, DocumentFieldTypeDecimal:
+			if r, ok := numericRat(v.Value); ok {
+				b.WriteString(r.RatString())
+			}
+		case DocumentFieldTypeBinary:
+			b.WriteString(hex.EncodeToString(v.Value.([]byte)))
+		case DocumentFieldTypeTime:
+			b.WriteString(v.Value.(time.Time).UTC().Format(time.RFC3339Nano))
+		}
+	}
+	return b.String()
+}
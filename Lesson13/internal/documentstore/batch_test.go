@@ -0,0 +1,206 @@
+package documentstore
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func batchDoc(id, email string, age int) Document {
+	return txDoc(id, map[string]any{"email": email, "age": age})
+}
+
+func newBatchCollection(t *testing.T) *Collection {
+	t.Helper()
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	if err := c.CreateIndexWithOptions(IndexOptions{Fields: []string{"email"}, Unique: true}); err != nil {
+		t.Fatalf("CreateIndexWithOptions() error = %v", err)
+	}
+	if err := c.CreateIndex("age"); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	if _, _, err := c.Put(batchDoc("1", "a@x", 30)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	return c
+}
+
+func batchErrors(results []BatchResult) []error {
+	errs := make([]error, len(results))
+	for i, r := range results {
+		errs[i] = r.Err
+	}
+	return errs
+}
+
+func TestCollection_PutMany(t *testing.T) {
+	missingKey := Document{Fields: map[string]DocumentField{"email": {Type: DocumentFieldTypeString, Value: "nokey@x"}}}
+
+	tests := []struct {
+		name     string
+		docs     []Document
+		mode     BatchMode
+		wantErrs []error // nil - елемент записано; для решти перевіряється errors.Is
+		wantErr  bool
+		wantIDs  []string // документи колекції після виклику, впорядковані за age
+	}{
+		{
+			name:     "All valid",
+			docs:     []Document{batchDoc("2", "b@x", 20), batchDoc("3", "c@x", 40)},
+			mode:     BatchAllOrNothing,
+			wantErrs: []error{nil, nil},
+			wantIDs:  []string{"2", "1", "3"},
+		},
+		{
+			name:     "All or nothing aborts on invalid document",
+			docs:     []Document{batchDoc("2", "b@x", 20), missingKey},
+			mode:     BatchAllOrNothing,
+			wantErrs: []error{ErrBatchAborted, errors.New("missing 'id' field")},
+			wantErr:  true,
+			wantIDs:  []string{"1"},
+		},
+		{
+			name:     "Best effort skips invalid document",
+			docs:     []Document{batchDoc("2", "b@x", 20), missingKey},
+			mode:     BatchBestEffort,
+			wantErrs: []error{nil, errors.New("missing 'id' field")},
+			wantIDs:  []string{"2", "1"},
+		},
+		{
+			name:     "Unique conflict with existing document",
+			docs:     []Document{batchDoc("2", "a@x", 20), batchDoc("3", "c@x", 40)},
+			mode:     BatchBestEffort,
+			wantErrs: []error{ErrUniqueViolation, nil},
+			wantIDs:  []string{"1", "3"},
+		},
+		{
+			name:     "Unique conflict inside batch",
+			docs:     []Document{batchDoc("2", "b@x", 20), batchDoc("3", "b@x", 40)},
+			mode:     BatchBestEffort,
+			wantErrs: []error{nil, ErrUniqueViolation},
+			wantIDs:  []string{"2", "1"},
+		},
+		{
+			name:     "Value freed by replaced document",
+			docs:     []Document{batchDoc("1", "z@x", 50), batchDoc("2", "a@x", 20)},
+			mode:     BatchAllOrNothing,
+			wantErrs: []error{nil, nil},
+			wantIDs:  []string{"2", "1"},
+		},
+		{
+			name:     "Value freed by duplicate key in batch",
+			docs:     []Document{batchDoc("2", "b@x", 20), batchDoc("2", "c@x", 25), batchDoc("3", "b@x", 40)},
+			mode:     BatchAllOrNothing,
+			wantErrs: []error{nil, nil, nil},
+			wantIDs:  []string{"2", "1", "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newBatchCollection(t)
+			results, err := c.PutMany(tt.docs, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PutMany() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for i, want := range tt.wantErrs {
+				got := results[i].Err
+				switch {
+				case want == nil && got != nil:
+					t.Errorf("item %d error = %v, want nil", i, got)
+				case want != nil && got == nil:
+					t.Errorf("item %d error = nil, want %v", i, want)
+				case want != nil && !errors.Is(got, want) && got.Error() != want.Error():
+					t.Errorf("item %d error = %v, want %v", i, got, want)
+				}
+				if got == nil && results[i].Version == 0 {
+					t.Errorf("item %d has no version", i)
+				}
+			}
+
+			docs, _ := c.Query("age", QueryParams{})
+			ids := make([]string, len(docs))
+			for i, d := range docs {
+				ids[i] = d.Fields["id"].Value.(string)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("Query() ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if c.NumDocuments() != len(tt.wantIDs) {
+				t.Errorf("NumDocuments() = %d, want %d", c.NumDocuments(), len(tt.wantIDs))
+			}
+		})
+	}
+}
+
+func TestCollection_PutManyMatchesSequentialPut(t *testing.T) {
+	var docs []Document
+	for i := 0; i < 200; i++ {
+		docs = append(docs, batchDoc(fmt.Sprint(i%150), fmt.Sprintf("u%d@x", i), (i*37)%50))
+	}
+	batch := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	sequential := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	for _, c := range []*Collection{batch, sequential} {
+		if err := c.CreateIndex("age"); err != nil {
+			t.Fatalf("CreateIndex() error = %v", err)
+		}
+	}
+
+	if _, err := batch.PutMany(docs, BatchAllOrNothing); err != nil {
+		t.Fatalf("PutMany() error = %v", err)
+	}
+	for _, d := range docs {
+		if _, _, err := sequential.Put(d); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	got, _ := batch.Query("age", QueryParams{})
+	want, _ := sequential.Query("age", QueryParams{})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PutMany() index differs from sequential Put()")
+	}
+}
+
+func TestCollection_DeleteMany(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []any
+		mode     BatchMode
+		wantErr  bool
+		wantErrs []error
+		wantLeft int
+	}{
+		{name: "All existing", keys: []any{"1", "2"}, mode: BatchAllOrNothing, wantErrs: []error{nil, nil}, wantLeft: 1},
+		{name: "All or nothing with missing key", keys: []any{"1", "404"}, mode: BatchAllOrNothing, wantErr: true, wantErrs: []error{ErrBatchAborted, ErrDocumentNotFound}, wantLeft: 3},
+		{name: "Best effort with missing key", keys: []any{"1", "404"}, mode: BatchBestEffort, wantErrs: []error{nil, ErrDocumentNotFound}, wantLeft: 2},
+		{name: "Component values", keys: []any{[]any{"3"}}, mode: BatchBestEffort, wantErrs: []error{nil}, wantLeft: 2},
+		{name: "All or nothing with duplicate key", keys: []any{"1", "1"}, mode: BatchAllOrNothing, wantErr: true, wantErrs: []error{ErrBatchAborted, ErrDocumentNotFound}, wantLeft: 3},
+		{name: "Best effort with duplicate key", keys: []any{"1", []any{"1"}}, mode: BatchBestEffort, wantErrs: []error{nil, ErrDocumentNotFound}, wantLeft: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newBatchCollection(t)
+			if _, err := c.PutMany([]Document{batchDoc("2", "b@x", 20), batchDoc("3", "c@x", 40)}, BatchAllOrNothing); err != nil {
+				t.Fatalf("PutMany() error = %v", err)
+			}
+			results, err := c.DeleteMany(tt.keys, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeleteMany() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for i, want := range tt.wantErrs {
+				if got := results[i].Err; !errors.Is(got, want) {
+					t.Errorf("item %d error = %v, want %v", i, got, want)
+				}
+			}
+			if c.NumDocuments() != tt.wantLeft {
+				t.Errorf("NumDocuments() = %d, want %d", c.NumDocuments(), tt.wantLeft)
+			}
+			for _, name := range []string{"age", "email"} {
+				if docs, _ := c.Query(name, QueryParams{}); len(docs) != tt.wantLeft {
+					t.Errorf("index '%s' has %d entries, want %d", name, len(docs), tt.wantLeft)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package documentstore
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrInvalidCap       = errors.New("invalid capped collection configuration")
+	ErrDocumentTooLarge = errors.New("document exceeds collection size limit")
+)
+
+// EvictionPolicy визначає, які документи видаляються з обмеженої колекції першими
+type EvictionPolicy string
+
+const (
+	EvictOldest EvictionPolicy = "oldest" // за порядком вставки (за замовчуванням); заміна документа порядок не змінює
+	EvictLRU    EvictionPolicy = "lru"    // найдавніше використані: запис і Get роблять документ "свіжим"
+)
+
+// CapStats - метрики обмеженої колекції
+type CapStats struct {
+	Documents int
+	Bytes     int64 // сумарний розмір документів (JSON полів)
+	Evictions uint64
+}
+
+// capTracker - порядок витіснення як у Lesson8 lru: map для пошуку та
+// двозв'язний список, де на початку найновіші (або нещодавно використані) документи
+type capTracker struct {
+	mu        sync.Mutex // Get під c.mu.RLock() теж змінює порядок для EvictLRU
+	items     map[string]*list.Element
+	order     *list.List
+	bytes     int64
+	evictions uint64
+}
+
+type capEntry struct {
+	key  string
+	size int64
+}
+
+func newCapTracker() *capTracker {
+	return &capTracker{items: make(map[string]*list.Element), order: list.New()}
+}
+
+func (cfg *CollectionConfig) capped() bool {
+	return cfg != nil && (cfg.MaxDocuments > 0 || cfg.MaxBytes > 0)
+}
+
+// checkCap перевіряє обмеження розміру конфігурації
+func (cfg *CollectionConfig) checkCap() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.MaxDocuments < 0 || cfg.MaxBytes < 0 {
+		return fmt.Errorf("%w: limits cannot be negative", ErrInvalidCap)
+	}
+	switch cfg.Eviction {
+	case "", EvictOldest, EvictLRU:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown eviction policy '%s'", ErrInvalidCap, cfg.Eviction)
+}
+
+func documentSize(doc Document) int64 {
+	data, err := json.Marshal(doc.Fields)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+// checkSizeLocked відхиляє документ, більший за MaxBytes: його не вмістить навіть порожня колекція
+func (c *Collection) checkSizeLocked(doc Document) error {
+	if c.config.MaxBytes <= 0 {
+		return nil
+	}
+	if size := documentSize(doc); size > c.config.MaxBytes {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrDocumentTooLarge, size, c.config.MaxBytes)
+	}
+	return nil
+}
+
+// trackLocked враховує записаний документ. Викликається під c.mu.Lock().
+func (c *Collection) trackLocked(key string, doc Document) {
+	if !c.config.capped() {
+		return
+	}
+	if c.cap == nil {
+		c.cap = newCapTracker()
+	}
+	t := c.cap
+	size := documentSize(doc)
+	if el, ok := t.items[key]; ok {
+		e := el.Value.(*capEntry)
+		t.bytes += size - e.size
+		e.size = size
+		if c.config.Eviction == EvictLRU {
+			t.order.MoveToFront(el)
+		}
+		return
+	}
+	t.items[key] = t.order.PushFront(&capEntry{key: key, size: size})
+	t.bytes += size
+}
+
+// untrackLocked прибирає видалений документ. Викликається під c.mu.Lock().
+func (c *Collection) untrackLocked(key string) {
+	if c.cap == nil {
+		return
+	}
+	if el, ok := c.cap.items[key]; ok {
+		c.cap.bytes -= el.Value.(*capEntry).size
+		c.cap.order.Remove(el)
+		delete(c.cap.items, key)
+	}
+}
+
+// touch позначає документ як використаний для EvictLRU.
+// Викликається під c.mu (достатньо RLock).
+func (c *Collection) touch(key string) {
+	if c.cap == nil || c.config.Eviction != EvictLRU {
+		return
+	}
+	c.cap.mu.Lock()
+	defer c.cap.mu.Unlock()
+	if el, ok := c.cap.items[key]; ok {
+		c.cap.order.MoveToFront(el)
+	}
+}
+
+// evictLocked видаляє найстаріші документи, поки колекція перевищує обмеження.
+// Документ protect (щойно записаний) не витісняється. Викликається під c.mu.Lock()
+// після публікації змін, тож події ChangeEvict йдуть за подією запису.
+func (c *Collection) evictLocked(protect string) {
+	if c.cap == nil {
+		return
+	}
+	t := c.cap
+	over := func() bool {
+		return (c.config.MaxDocuments > 0 && t.order.Len() > c.config.MaxDocuments) ||
+			(c.config.MaxBytes > 0 && t.bytes > c.config.MaxBytes)
+	}
+	for el := t.order.Back(); el != nil && over(); {
+		prev := el.Prev()
+		key := el.Value.(*capEntry).key
+		if key != protect {
+			doc := c.documents[key]
+			delete(c.documents, key)
+			c.removeFromIndexes(key, doc)
+			c.untrackLocked(key)
+			t.evictions++
+			c.publishLocked(deleteEvent(ChangeEvict, key, doc))
+			slog.Debug("Evict: document evicted", slog.String("key", key))
+		}
+		el = prev
+	}
+}
+
+// rebuildCap відновлює порядок витіснення після відновлення з дампу:
+// порядок вставки не зберігається, тому документи впорядковуються за версією
+func (c *Collection) rebuildCap() {
+	if !c.config.capped() {
+		return
+	}
+	keys := make([]string, 0, len(c.documents))
+	for key := range c.documents {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.documents[keys[i]].Version < c.documents[keys[j]].Version
+	})
+	for _, key := range keys {
+		c.trackLocked(key, c.documents[key])
+	}
+}
+
+// CapStats повертає метрики обмеженої колекції; для необмеженої - лише кількість документів
+func (c *Collection) CapStats() CapStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	stats := CapStats{Documents: len(c.documents)}
+	if c.cap != nil {
+		stats.Bytes = c.cap.bytes
+		stats.Evictions = c.cap.evictions
+	}
+	return stats
+}
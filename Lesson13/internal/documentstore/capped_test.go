@@ -0,0 +1,244 @@
+package documentstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func docIDs(docs []Document) []string {
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.Fields["id"].Value.(string))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestCollection_Capped(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CollectionConfig
+		write   func(c *Collection) error
+		wantIDs []string
+	}{
+		{
+			name:   "Oldest is evicted",
+			config: CollectionConfig{PrimaryKey: "id", MaxDocuments: 2},
+			write: func(c *Collection) error {
+				for _, id := range []string{"1", "2", "3"} {
+					if _, _, err := c.Put(txDoc(id, map[string]any{"n": 1})); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			wantIDs: []string{"2", "3"},
+		},
+		{
+			name:   "Replace keeps insertion order",
+			config: CollectionConfig{PrimaryKey: "id", MaxDocuments: 2},
+			write: func(c *Collection) error {
+				for _, id := range []string{"1", "2", "1", "3"} {
+					if _, _, err := c.Put(txDoc(id, map[string]any{"n": 1})); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			wantIDs: []string{"2", "3"},
+		},
+		{
+			name:   "Get protects document from LRU eviction",
+			config: CollectionConfig{PrimaryKey: "id", MaxDocuments: 2, Eviction: EvictLRU},
+			write: func(c *Collection) error {
+				for _, id := range []string{"1", "2"} {
+					if _, _, err := c.Put(txDoc(id, map[string]any{"n": 1})); err != nil {
+						return err
+					}
+				}
+				if _, err := c.Get("1"); err != nil {
+					return err
+				}
+				_, _, err := c.Put(txDoc("3", map[string]any{"n": 1}))
+				return err
+			},
+			wantIDs: []string{"1", "3"},
+		},
+		{
+			name:   "Batch keeps newest",
+			config: CollectionConfig{PrimaryKey: "id", MaxDocuments: 2},
+			write: func(c *Collection) error {
+				docs := []Document{txDoc("1", map[string]any{"n": 1}), txDoc("2", map[string]any{"n": 1}), txDoc("3", map[string]any{"n": 1})}
+				_, err := c.PutMany(docs, BatchAllOrNothing)
+				return err
+			},
+			wantIDs: []string{"2", "3"},
+		},
+		{
+			name:   "Byte limit",
+			config: CollectionConfig{PrimaryKey: "id", MaxBytes: 2 * documentSize(txDoc("1", map[string]any{"n": 1}))},
+			write: func(c *Collection) error {
+				for _, id := range []string{"1", "2", "3"} {
+					if _, _, err := c.Put(txDoc(id, map[string]any{"n": 1})); err != nil {
+						return err
+					}
+				}
+				_, _, err := c.Put(txDoc("4", map[string]any{"n": 1, "s": strings.Repeat("x", 100)}))
+				if !errors.Is(err, ErrDocumentTooLarge) {
+					return errors.New("oversized document accepted")
+				}
+				return nil
+			},
+			wantIDs: []string{"2", "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			if err := s.CreateCollection("c", &tt.config); err != nil {
+				t.Fatalf("CreateCollection() error = %v", err)
+			}
+			c, _ := s.GetCollection("c")
+			if err := c.CreateIndex("n"); err != nil {
+				t.Fatalf("CreateIndex() error = %v", err)
+			}
+			if err := tt.write(c); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			if got := docIDs(c.List()); !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("List() ids = %v, want %v", got, tt.wantIDs)
+			}
+			// Витіснені документи прибираються й з індексів
+			docs, _ := c.Query("n", QueryParams{})
+			if got := docIDs(docs); !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("Query() ids = %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestCollection_CappedEvents(t *testing.T) {
+	s := newTxStore(t)
+	if err := s.CreateCollection("log", &CollectionConfig{PrimaryKey: "id", MaxDocuments: 1}); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	c, _ := s.GetCollection("log")
+	ch, err := c.Watch(context.Background(), WatchFilter{Ops: []ChangeOp{ChangeEvict}})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	var hooked []string
+	c.AddAfterHook(func(ev ChangeEvent) error {
+		if ev.Op == ChangeEvict {
+			hooked = append(hooked, ev.Key)
+		}
+		return nil
+	})
+
+	for _, id := range []string{"1", "2", "3"} {
+		if _, _, err := c.Put(txDoc(id, nil)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	want := []eventSummary{
+		{Seq: 3, Op: ChangeEvict, Key: "1", Before: true},
+		{Seq: 5, Op: ChangeEvict, Key: "2", Before: true},
+	}
+	if got, _ := drain(ch); !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch() events = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(hooked, []string{"1", "2"}) {
+		t.Errorf("after hook evictions = %v, want [1 2]", hooked)
+	}
+	if stats := c.CapStats(); stats.Documents != 1 || stats.Evictions != 2 {
+		t.Errorf("CapStats() = %+v, want 1 document and 2 evictions", stats)
+	}
+}
+
+func TestTx_CappedRollbackDoesNotEvict(t *testing.T) {
+	s := newTxStore(t)
+	if err := s.CreateCollection("users", &CollectionConfig{PrimaryKey: "id", MaxDocuments: 2}); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	users, _ := s.GetCollection("users")
+	if err := users.CreateIndexWithOptions(IndexOptions{Fields: []string{"email"}, Unique: true}); err != nil {
+		t.Fatalf("CreateIndexWithOptions() error = %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if _, _, err := users.Put(txDoc(id, map[string]any{"email": id + "@x"})); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	failed := s.Begin()
+	failed.Put("users", txDoc("3", map[string]any{"email": "3@x"}))
+	failed.Put("users", txDoc("4", map[string]any{"email": "1@x"}))
+	if err := failed.Commit(); !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("Commit() error = %v, wantErr %v", err, ErrUniqueViolation)
+	}
+	if got := docIDs(users.List()); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("List() after rollback = %v, want [1 2]", got)
+	}
+
+	ok := s.Begin()
+	ok.Put("users", txDoc("3", map[string]any{"email": "3@x"}))
+	if err := ok.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got := docIDs(users.List()); !reflect.DeepEqual(got, []string{"2", "3"}) {
+		t.Errorf("List() after Commit() = %v, want [2 3]", got)
+	}
+	if stats := users.CapStats(); stats.Evictions != 1 {
+		t.Errorf("CapStats().Evictions = %d, want 1", stats.Evictions)
+	}
+}
+
+func TestStore_CreateCappedCollectionInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config CollectionConfig
+	}{
+		{name: "Negative documents", config: CollectionConfig{PrimaryKey: "id", MaxDocuments: -1}},
+		{name: "Negative bytes", config: CollectionConfig{PrimaryKey: "id", MaxBytes: -1}},
+		{name: "Unknown policy", config: CollectionConfig{PrimaryKey: "id", MaxDocuments: 1, Eviction: "random"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewStore().CreateCollection("c", &tt.config); !errors.Is(err, ErrInvalidCap) {
+				t.Errorf("CreateCollection() error = %v, wantErr %v", err, ErrInvalidCap)
+			}
+		})
+	}
+}
+
+func TestCollection_CappedRestore(t *testing.T) {
+	s := newTxStore(t)
+	if err := s.CreateCollection("c", &CollectionConfig{PrimaryKey: "id", MaxDocuments: 2}); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	c, _ := s.GetCollection("c")
+	for _, id := range []string{"1", "2"} {
+		if _, _, err := c.Put(txDoc(id, nil)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	dump, err := s.Dump()
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	restored, err := NewStoreFromDump(dump)
+	if err != nil {
+		t.Fatalf("NewStoreFromDump() error = %v", err)
+	}
+	rc, _ := restored.GetCollection("c")
+	if _, _, err := rc.Put(txDoc("3", nil)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := docIDs(rc.List()); !reflect.DeepEqual(got, []string{"2", "3"}) {
+		t.Errorf("List() after restore = %v, want [2 3]", got)
+	}
+}
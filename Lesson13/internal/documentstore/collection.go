@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"sort"
+	"sync"
+	"time"
 )
 
 var (
@@ -14,61 +16,156 @@ var (
 	ErrInvalidFieldType = errors.New("invalid field type")
 	ErrIndexExists      = errors.New("index already exists")
 	ErrIndexNotFound    = errors.New("index does not exist")
+	ErrUniqueViolation  = errors.New("unique index violation")
+	ErrVersionConflict  = errors.New("document version conflict")
+
+	ErrDocumentAlreadyExists = errors.New("document already exists")
 )
 
 type Collection struct {
+	mu        sync.RWMutex // захищає documents, indexes, keys, revision і підписки
 	config    *CollectionConfig
 	documents map[string]Document
 	indexes   map[string]*Index
+	keys      keyGenerator
+	revision  uint64 // остання видана версія документа; спільна для колекції, тож версії не повторюються після видалення
+	changeSeq uint64 // номер останньої події Watch
+	changes   []ChangeEvent
+	watchers  map[*watcher]struct{}
+
+	cap *capTracker // порядок витіснення; лише для обмежених колекцій
+
+	beforeHooks  []BeforeHook
+	afterHooks   []AfterHook
+	pendingAfter []ChangeEvent // події для after-хуків, які викличе поточний власник блокування
 }
 
 type CollectionConfig struct {
-	PrimaryKey string
+	PrimaryKey     string         `json:"primaryKey"`
+	PrimaryKeys    []string       `json:"primaryKeys,omitempty"` // складений ключ: поля в порядку компонентів, замість PrimaryKey
+	Schema         *Schema        `json:"schema,omitempty"`
+	ValidationMode ValidationMode `json:"validationMode,omitempty"` // за замовчуванням strict, якщо задано Schema
+	KeyGeneration  KeyGeneration  `json:"keyGeneration,omitempty"`  // генерація ключа для документів без PrimaryKey
+	// TTL - час життя документа від останнього запису (у JSON - наносекунди)
+	TTL time.Duration `json:"ttl,omitempty"`
+	// ExpiresAtField - поле з часом, коли документ застаріває; має пріоритет над TTL
+	ExpiresAtField string `json:"expiresAtField,omitempty"`
+	// MaxDocuments і MaxBytes обмежують колекцію; при перевищенні витісняються документи за Eviction
+	MaxDocuments int            `json:"maxDocuments,omitempty"`
+	MaxBytes     int64          `json:"maxBytes,omitempty"` // сумарний розмір JSON полів документів
+	Eviction     EvictionPolicy `json:"eviction,omitempty"`
+}
+
+// check виконує всі перевірки конфігурації - для CreateCollection і для
+// конфігурацій, відновлених з дампу. Разом з помилкою повертає опис для журналу.
+func (cfg *CollectionConfig) check() (string, error) {
+	if cfg == nil {
+		return "Відсутня конфігурація колекції", fmt.Errorf("%w: collection config is missing", ErrInvalidPrimaryKey)
+	}
+	checks := []struct {
+		message string
+		check   func() error
+	}{
+		{message: "Невалідний первинний ключ колекції", check: cfg.checkKeys},
+		{message: "Невалідні обмеження розміру колекції", check: cfg.checkCap},
+		{message: "Невалідний термін дії документів колекції", check: cfg.checkExpiry},
+		{message: "Невідома стратегія генерації ключів для колекції", check: cfg.KeyGeneration.check},
+		{message: "Невалідна схема колекції", check: cfg.checkSchema},
+	}
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			return c.message, err
+		}
+	}
+	return "", nil
 }
 
 type QueryParams struct {
 	Desc     bool
 	MinValue *string
 	MaxValue *string
+	// Min і Max - типізовані межі (наприклад, числові); мають пріоритет над MinValue/MaxValue
+	Min *DocumentField
+	Max *DocumentField
 }
 
 type Index struct {
-	FieldName string
+	FieldName string   // ім'я індексу; для простого індексу збігається з назвою поля
+	Fields    []string // поля складеного індексу; порожньо для простого
+	Unique    bool
 	Sorted    []indexedEntry
 }
 
 type indexedEntry struct {
 	Key      string
 	Document Document
+	values   []DocumentField // значення полів індексу, обчислені при вставці
+}
+
+// IndexOptions описує індекс для CreateIndexWithOptions
+type IndexOptions struct {
+	Name   string   // за замовчуванням - назва першого поля
+	Fields []string // кілька полів утворюють складений індекс
+	Unique bool
 }
 
 func (c *Collection) CreateIndex(fieldName string) error {
+	return c.CreateIndexWithOptions(IndexOptions{Fields: []string{fieldName}})
+}
+
+func (c *Collection) CreateIndexWithOptions(opts IndexOptions) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(opts.Fields) == 0 {
+		return fmt.Errorf("%w: index must have at least one field", ErrInvalidFieldType)
+	}
+	name := opts.Name
+	if name == "" {
+		name = opts.Fields[0]
+	}
 	if c.indexes == nil {
 		c.indexes = make(map[string]*Index)
 	}
-	if _, exists := c.indexes[fieldName]; exists {
+	if _, exists := c.indexes[name]; exists {
 		return ErrIndexExists
 	}
 
+	index := &Index{FieldName: name, Unique: opts.Unique}
+	if len(opts.Fields) > 1 || opts.Fields[0] != name {
+		index.Fields = opts.Fields
+	}
+
 	var sorted []indexedEntry
+	now := timeNow()
 	for k, doc := range c.documents {
-		field, ok := doc.Fields[fieldName]
-		if !ok || field.Type != DocumentFieldTypeString {
+		if doc.expired(now) {
+			continue
+		}
+		values, ok := index.values(doc)
+		if !ok {
 			continue
 		}
-		sorted = append(sorted, indexedEntry{Key: k, Document: doc})
+		sorted = append(sorted, indexedEntry{Key: k, Document: doc, values: values})
 	}
-	sort.Slice(sorted, func(i, j int) bool {
-		vi := sorted[i].Document.Fields[fieldName].Value.(string)
-		vj := sorted[j].Document.Fields[fieldName].Value.(string)
-		return vi < vj
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareIndexKeys(sorted[i].values, sorted[j].values) < 0
 	})
+	if index.Unique {
+		for i := 1; i < len(sorted); i++ {
+			if !allNull(sorted[i].values) && compareIndexKeys(sorted[i-1].values, sorted[i].values) == 0 {
+				return fmt.Errorf("%w: index '%s', documents '%s' and '%s'", ErrUniqueViolation, name, sorted[i-1].Key, sorted[i].Key)
+			}
+		}
+	}
 
-	c.indexes[fieldName] = &Index{FieldName: fieldName, Sorted: sorted}
+	index.Sorted = sorted
+	c.indexes[name] = index
 	return nil
 }
 
 func (c *Collection) DeleteIndex(fieldName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.indexes == nil {
 		return ErrIndexNotFound
 	}
@@ -80,18 +177,22 @@ func (c *Collection) DeleteIndex(fieldName string) error {
 }
 
 func (c *Collection) Query(fieldName string, params QueryParams) ([]Document, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	index, exists := c.indexes[fieldName]
 	if !exists {
 		return nil, ErrIndexNotFound
 	}
+	params, err := params.normalize()
+	if err != nil {
+		return nil, err
+	}
 
 	var result []Document
+	now := timeNow()
 	for _, entry := range index.Sorted {
-		val := entry.Document.Fields[fieldName].Value.(string)
-		if params.MinValue != nil && val < *params.MinValue {
-			continue
-		}
-		if params.MaxValue != nil && val > *params.MaxValue {
+		// Для складеного індексу межі запиту застосовуються до першого поля
+		if !params.matches(entry.values[0]) || entry.Document.expired(now) {
 			continue
 		}
 		result = append(result, entry.Document)
@@ -106,108 +207,287 @@ func (c *Collection) Query(fieldName string, params QueryParams) ([]Document, er
 	return result, nil
 }
 
-func (c *Collection) Put(doc Document) error {
+// Put додає або замінює документ і повертає його ключ та нову версію.
+// Якщо ключа немає, а в конфігурації задано KeyGeneration, ключ генерується.
+// Версія документа при цьому збільшується, але не перевіряється (див. PutIfVersion).
+func (c *Collection) Put(doc Document) (string, uint64, error) {
+	return c.put(doc, nil, writeUpsert)
+}
+
+// Insert додає новий документ і повертає його ключ.
+// Якщо документ з таким ключем уже є, повертається ErrDocumentAlreadyExists.
+func (c *Collection) Insert(doc Document) (string, error) {
+	key, _, err := c.put(doc, nil, writeInsert)
+	return key, err
+}
+
+// Replace замінює наявний документ і повертає його ключ.
+// Якщо документа з таким ключем немає, повертається ErrDocumentNotFound.
+func (c *Collection) Replace(doc Document) (string, error) {
+	key, _, err := c.put(doc, nil, writeReplace)
+	return key, err
+}
+
+// PutIfVersion записує документ, лише якщо збережена версія дорівнює version,
+// і повертає ключ та нову версію. version == 0 означає "документа ще немає".
+// Якщо версія інша, повертається ErrVersionConflict.
+func (c *Collection) PutIfVersion(doc Document, version uint64) (string, uint64, error) {
+	return c.put(doc, &version, writeUpsert)
+}
+
+// writeMode визначає, чи може put створити або замінити документ
+type writeMode int
+
+const (
+	writeUpsert  writeMode = iota // створити або замінити
+	writeInsert                   // лише створити
+	writeReplace                  // лише замінити
+)
+
+func (c *Collection) put(doc Document, expected *uint64, mode writeMode) (string, uint64, error) {
 	if c.config == nil {
-		return errors.New("collection config is not initialized")
+		return "", 0, errors.New("collection config is not initialized")
 	}
-	keyField, ok := doc.Fields[c.config.PrimaryKey]
-	if !ok {
-		return fmt.Errorf("missing '%s' field", c.config.PrimaryKey)
+	c.mu.Lock()
+	defer c.unlockAndRunHooks()
+
+	if c.documents == nil {
+		c.documents = make(map[string]Document)
+	}
+	doc, err := c.assignKey(doc)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := c.validate(doc); err != nil {
+		return "", 0, err
+	}
+	key, err := c.documentKey(doc)
+	if err != nil {
+		return "", 0, err
+	}
+	existing, exists := c.liveLocked(key)
+	switch {
+	case mode == writeInsert && exists:
+		return "", 0, fmt.Errorf("%w: key '%s'", ErrDocumentAlreadyExists, key)
+	case mode == writeReplace && !exists:
+		return "", 0, fmt.Errorf("%w: key '%s'", ErrDocumentNotFound, key)
+	}
+	// Версію перевіряємо до хуків, щоб конфлікт не запускав їх даремно
+	if expected != nil {
+		if err := checkVersion(key, existing.Version, *expected); err != nil {
+			return "", 0, err
+		}
 	}
-	if keyField.Type != DocumentFieldTypeString {
-		return ErrInvalidKeyType
+	if doc, err = c.beforePutLocked(ChangePut, key, doc); err != nil {
+		return "", 0, err
 	}
 
-	key, ok := keyField.Value.(string)
-	if !ok || key == "" {
-		return ErrEmptyKey
+	ev, err := c.storeLocked(key, doc)
+	if err != nil {
+		return "", 0, err
 	}
+	c.publishLocked(ev)
+	c.evictLocked(key)
+	return key, ev.After.Version, nil
+}
 
-	if c.documents == nil {
-		c.documents = make(map[string]Document)
+// storeLocked записує документ з уже обчисленим ключем: перевіряє термін дії,
+// розмір та унікальні індекси, призначає нову версію й оновлює індекси.
+// Повертає подію ChangePut, яку викликач публікує, коли зміна остаточна.
+// Викликається під c.mu.Lock().
+func (c *Collection) storeLocked(key string, doc Document) (ChangeEvent, error) {
+	existing, exists := c.liveLocked(key)
+	expiresAt, err := c.expiresAt(doc, timeNow())
+	if err != nil {
+		return ChangeEvent{}, err
+	}
+	if err := c.checkSizeLocked(doc); err != nil {
+		return ChangeEvent{}, err
+	}
+	if err := c.checkUnique(key, doc); err != nil {
+		return ChangeEvent{}, err
 	}
 
-	if _, exists := c.documents[key]; exists {
+	if exists {
 		slog.Debug("Put: replacing existing document", slog.String("key", key))
 	} else {
 		slog.Debug("Put: adding new document", slog.String("key", key))
 	}
 
+	c.revision = max(c.revision, existing.Version) + 1
+	doc.Version = c.revision
+	doc.ExpiresAt = expiresAt
 	c.documents[key] = doc
 	c.updateIndexes(key, doc)
+	c.trackLocked(key, doc)
+	return putEvent(ChangePut, key, existing, exists, doc), nil
+}
+
+// checkVersion порівнює збережену версію з очікуваною
+func checkVersion(key string, stored, expected uint64) error {
+	if stored != expected {
+		return fmt.Errorf("%w: document '%s' has version %d, expected %d", ErrVersionConflict, key, stored, expected)
+	}
 	return nil
 }
 
-func (c *Collection) Delete(key string) error {
+// assignKey генерує ключ для документа без PrimaryKey (відсутнє поле, null або "").
+// Документ копіюється, щоб не змінювати map, передану викликачем.
+func (c *Collection) assignKey(doc Document) (Document, error) {
+	if c.config.KeyGeneration == KeyGenerationNone {
+		return doc, nil
+	}
+	if keyField, ok := doc.Fields[c.config.PrimaryKey]; ok {
+		if s, isString := keyField.Value.(string); keyField.Type != DocumentFieldTypeNull && (!isString || s != "") {
+			return doc, nil
+		}
+	}
+
+	key, err := c.keys.nextKey(c.config.KeyGeneration, func(k string) bool {
+		_, exists := c.documents[k]
+		return exists
+	})
+	if err != nil {
+		return doc, err
+	}
+	fields := make(map[string]DocumentField, len(doc.Fields)+1)
+	for k, v := range doc.Fields {
+		fields[k] = v
+	}
+	fields[c.config.PrimaryKey] = DocumentField{Type: DocumentFieldTypeString, Value: key}
+	slog.Debug("Put: generated key", slog.String("key", key), slog.String("strategy", string(c.config.KeyGeneration)))
+	return Document{Fields: fields}, nil
+}
+
+// validate перевіряє документ схемою колекції відповідно до ValidationMode
+func (c *Collection) validate(doc Document) error {
+	if c.config.Schema == nil || c.config.ValidationMode == ValidationModeOff {
+		return nil
+	}
+	err := c.config.Schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	if c.config.ValidationMode == ValidationModeWarn {
+		slog.Warn("Put: document does not match schema", slog.Any("error", err))
+		return nil
+	}
+	return err
+}
+
+// Delete видаляє документ. Для складеного ключа передаються значення всіх компонентів.
+func (c *Collection) Delete(keyValues ...any) error {
+	return c.delete(keyValues, nil)
+}
+
+// DeleteIfVersion видаляє документ, лише якщо його версія дорівнює version;
+// інакше повертає ErrVersionConflict.
+func (c *Collection) DeleteIfVersion(version uint64, keyValues ...any) error {
+	return c.delete(keyValues, &version)
+}
+
+func (c *Collection) delete(keyValues []any, expected *uint64) error {
+	c.mu.Lock()
+	defer c.unlockAndRunHooks()
+
 	if c.documents == nil {
 		return ErrDocumentNotFound
 	}
-	doc, ok := c.documents[key]
+	key, err := c.lookupKey(keyValues)
+	if err != nil {
+		return err
+	}
+	existing, ok := c.liveLocked(key)
 	if !ok {
 		return ErrDocumentNotFound
 	}
+	// Версію перевіряємо до хуків, щоб конфлікт не запускав їх даремно
+	if expected != nil {
+		if err := checkVersion(key, existing.Version, *expected); err != nil {
+			return err
+		}
+	}
+	if err := c.beforeDeleteLocked(key); err != nil {
+		return err
+	}
+	doc, err := c.removeLocked(key)
+	if err != nil {
+		return err
+	}
+	c.publishLocked(deleteEvent(ChangeDelete, key, doc))
+	return nil
+}
+
+// removeLocked видаляє документ за внутрішнім ключем і повертає видалений документ.
+// Викликається під c.mu.Lock().
+func (c *Collection) removeLocked(key string) (Document, error) {
+	doc, ok := c.liveLocked(key)
+	if !ok {
+		return Document{}, ErrDocumentNotFound
+	}
 	delete(c.documents, key)
 	c.removeFromIndexes(key, doc)
-	return nil
+	c.untrackLocked(key)
+	return doc, nil
 }
 
 func (c *Collection) updateIndexes(key string, doc Document) {
 	if c.indexes == nil {
 		return
 	}
-	for field, index := range c.indexes {
-		fieldVal, ok := doc.Fields[field]
-		if !ok || fieldVal.Type != DocumentFieldTypeString {
-			continue
-		}
-
-		valueToInsert, ok := fieldVal.Value.(string)
-		if !ok {
-			slog.Error("updateIndexes: potential panic - field value is not a string",
-				slog.String("key", key),
-				slog.String("field", field),
-				slog.Any("value_type", fmt.Sprintf("%T", fieldVal.Value)),
-			)
-			continue // Skip this index if the value is not a string
-		}
-
-		// Create a new filtered slice to build the updated index
+	for _, index := range c.indexes {
+		// Drop the previous entry for this key, even if the new value is not indexable
 		filtered := index.Sorted[:0]
-		removed := false
-
-		// Iterate through the existing sorted index and add elements to the filtered slice
 		for _, e := range index.Sorted {
-			_, ok := e.Document.Fields[field].Value.(string)
-			if !ok {
-				slog.Error("updateIndexes: potential panic - existing field value is not a string",
-					slog.String("existing_key", e.Key),
-					slog.String("field", field),
-					slog.Any("value_type", fmt.Sprintf("%T", e.Document.Fields[field].Value)),
-				)
-				continue // Skip this entry if the existing value is not a string (shouldn't happen if index creation is correct)
+			if e.Key != key {
+				filtered = append(filtered, e)
 			}
+		}
 
-			if !removed && e.Key == key {
-				removed = true
-				continue
-			}
-			filtered = append(filtered, e)
+		values, ok := index.values(doc)
+		if !ok {
+			index.Sorted = filtered
+			continue
 		}
 
 		// Find the insertion point for the new entry
 		insertIndex := sort.Search(len(filtered), func(i int) bool {
-			v, ok := filtered[i].Document.Fields[field].Value.(string)
-			return ok && v >= valueToInsert
+			return compareIndexKeys(filtered[i].values, values) > 0
 		})
 
 		// Insert the new entry at the correct position
-		filtered = append(filtered[:insertIndex], append([]indexedEntry{{Key: key, Document: doc}}, filtered[insertIndex:]...)...)
+		filtered = append(filtered[:insertIndex], append([]indexedEntry{{Key: key, Document: doc, values: values}}, filtered[insertIndex:]...)...)
 
 		// Update the index's Sorted slice with the new filtered and inserted data
 		index.Sorted = filtered
 	}
 }
 
+// checkUnique перевіряє унікальні індекси до запису документа.
+// Значення, де всі поля null або відсутні, унікальність не порушують.
+func (c *Collection) checkUnique(key string, doc Document) error {
+	now := timeNow()
+	for _, index := range c.indexes {
+		if !index.Unique {
+			continue
+		}
+		values, ok := index.values(doc)
+		if !ok || allNull(values) {
+			continue
+		}
+		pos := sort.Search(len(index.Sorted), func(i int) bool {
+			return compareIndexKeys(index.Sorted[i].values, values) >= 0
+		})
+		for i := pos; i < len(index.Sorted) && compareIndexKeys(index.Sorted[i].values, values) == 0; i++ {
+			// Прострочений документ значення не займає, навіть якщо його ще не видалено
+			if index.Sorted[i].Key != key && !index.Sorted[i].Document.expired(now) {
+				return fmt.Errorf("%w: index '%s', existing document '%s'", ErrUniqueViolation, index.FieldName, index.Sorted[i].Key)
+			}
+		}
+	}
+	return nil
+}
+
 func (c *Collection) removeFromIndexes(key string, doc Document) {
 	if c.indexes == nil {
 		return
@@ -223,42 +503,68 @@ func (c *Collection) removeFromIndexes(key string, doc Document) {
 	}
 }
 
-func (c *Collection) Get(key string) (*Document, error) {
+// Get повертає документ за ключем. Для складеного ключа передаються значення
+// всіх компонентів у порядку PrimaryKeys, наприклад Get("acc-1", "2024-01-31").
+func (c *Collection) Get(keyValues ...any) (*Document, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	if c.documents == nil {
 		return nil, ErrDocumentNotFound
 	}
-	doc, ok := c.documents[key]
+	key, err := c.lookupKey(keyValues)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := c.liveLocked(key)
 	if !ok {
 		return nil, ErrDocumentNotFound
 	}
+	c.touch(key)
 	return &doc, nil
 }
 
 func (c *Collection) List() []Document {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	if c.documents == nil {
 		return []Document{}
 	}
-	docs := make([]Document, 0, len(c.documents))
-	for _, doc := range c.documents {
-		docs = append(docs, doc)
-	}
-	return docs
+	return c.liveDocumentsLocked()
 }
 
 func (c *Collection) NumDocuments() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	if c.documents == nil {
 		return 0
 	}
-	return len(c.documents)
+	n := 0
+	now := timeNow()
+	for _, doc := range c.documents {
+		if !doc.expired(now) {
+			n++
+		}
+	}
+	return n
 }
 
 func (c *Collection) GetAll() ([]Document, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	if c.documents == nil {
 		return nil, errors.New("no documents in collection")
 	}
+	return c.liveDocumentsLocked(), nil
+}
+
+// liveDocumentsLocked повертає всі непрострочені документи. Викликається під c.mu.
+func (c *Collection) liveDocumentsLocked() []Document {
+	now := timeNow()
 	docs := make([]Document, 0, len(c.documents))
 	for _, doc := range c.documents {
-		docs = append(docs, doc)
+		if !doc.expired(now) {
+			docs = append(docs, doc)
+		}
 	}
-	return docs, nil
+	return docs
 }
@@ -1,8 +1,13 @@
 package documentstore
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestCollection_Delete(t *testing.T) {
@@ -300,7 +305,7 @@ func TestCollection_Put(t *testing.T) {
 				documents: tt.fields.documents,
 			}
 			initialLen := len(c.documents)
-			err := c.Put(tt.args.doc)
+			_, _, err := c.Put(tt.args.doc)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Put() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -311,10 +316,371 @@ func TestCollection_Put(t *testing.T) {
 				if tt.name == "Put new document" && len(c.documents) != initialLen+1 {
 					t.Errorf("Put() document count mismatch after adding")
 				}
-				if tt.name == "Put existing document (update)" && !reflect.DeepEqual(c.documents["1"], doc3) {
+				// Кожен запис отримує нову версію
+				updated := doc3
+				updated.Version = 1
+				if tt.name == "Put existing document (update)" && !reflect.DeepEqual(c.documents["1"], updated) {
 					t.Errorf("Put() document not updated correctly")
 				}
 			}
 		})
 	}
 }
+
+func TestCollection_QueryNullValues(t *testing.T) {
+	config := &CollectionConfig{PrimaryKey: "id"}
+	c := &Collection{config: config, documents: map[string]Document{}}
+	if err := c.CreateIndex("city"); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	docs := []Document{
+		{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "1"}, "city": {Type: DocumentFieldTypeString, Value: "Lviv"}}},
+		{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "2"}, "city": {Type: DocumentFieldTypeNull, Value: nil}}},
+		{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "3"}, "city": {Type: DocumentFieldTypeString, Value: "Kyiv"}}},
+	}
+	for _, doc := range docs {
+		if _, _, err := c.Put(doc); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	min := "A"
+	tests := []struct {
+		name   string
+		params QueryParams
+		want   []string
+	}{
+		{name: "Nulls sort first", params: QueryParams{}, want: []string{"2", "3", "1"}},
+		{name: "Descending", params: QueryParams{Desc: true}, want: []string{"1", "3", "2"}},
+		{name: "Lower bound excludes nulls", params: QueryParams{MinValue: &min}, want: []string{"3", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Query("city", tt.params)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			keys := make([]string, 0, len(got))
+			for _, doc := range got {
+				keys = append(keys, doc.Fields["id"].Value.(string))
+			}
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("Query() got = %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollection_QueryMixedNumbers(t *testing.T) {
+	config := &CollectionConfig{PrimaryKey: "id"}
+	c := &Collection{config: config, documents: map[string]Document{}}
+	if err := c.CreateIndex("score"); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	docs := []Document{
+		{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "int"}, "score": {Type: DocumentFieldTypeInteger, Value: int64(10)}}},
+		{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "float"}, "score": {Type: DocumentFieldTypeNumber, Value: 9.5}}},
+		{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "big"}, "score": {Type: DocumentFieldTypeInteger, Value: int64(9007199254740993)}}},
+		{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "decimal"}, "score": {Type: DocumentFieldTypeDecimal, Value: json.Number("10.000000000000000001")}}},
+		{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "text"}, "score": {Type: DocumentFieldTypeString, Value: "high"}}},
+	}
+	for _, doc := range docs {
+		if _, _, err := c.Put(doc); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		params QueryParams
+		want   []string
+	}{
+		{name: "Numbers before strings", params: QueryParams{}, want: []string{"float", "int", "decimal", "big", "text"}},
+		{
+			name:   "Float bound against integers",
+			params: QueryParams{Min: &DocumentField{Type: DocumentFieldTypeNumber, Value: 9.9}, Max: &DocumentField{Type: DocumentFieldTypeNumber, Value: 10.0}},
+			want:   []string{"int"},
+		},
+		{
+			name:   "Integer bound above 2^53",
+			params: QueryParams{Min: &DocumentField{Type: DocumentFieldTypeInteger, Value: int64(9007199254740993)}},
+			want:   []string{"big"},
+		},
+		{
+			name:   "Go int bound",
+			params: QueryParams{Min: &DocumentField{Type: DocumentFieldTypeInteger, Value: 10}},
+			want:   []string{"int", "decimal", "big"},
+		},
+		{
+			name:   "Go float32 and uint8 bounds",
+			params: QueryParams{Min: &DocumentField{Type: DocumentFieldTypeNumber, Value: float32(9.5)}, Max: &DocumentField{Type: DocumentFieldTypeInteger, Value: uint8(10)}},
+			want:   []string{"float", "int"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Query("score", tt.params)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			keys := make([]string, 0, len(got))
+			for _, doc := range got {
+				keys = append(keys, doc.Fields["id"].Value.(string))
+			}
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("Query() got = %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollection_QueryInvalidBounds(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}, documents: map[string]Document{}}
+	if err := c.CreateIndex("score"); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	if _, _, err := c.Put(txDoc("1", map[string]any{"score": 1})); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params QueryParams
+	}{
+		{name: "String type with number value", params: QueryParams{Min: &DocumentField{Type: DocumentFieldTypeString, Value: 5}}},
+		{name: "Integer type with string value", params: QueryParams{Max: &DocumentField{Type: DocumentFieldTypeInteger, Value: "5"}}},
+		{name: "NaN", params: QueryParams{Min: &DocumentField{Type: DocumentFieldTypeNumber, Value: math.NaN()}}},
+		{name: "Bool", params: QueryParams{Min: &DocumentField{Type: DocumentFieldTypeBool, Value: true}}},
+		{name: "Null", params: QueryParams{Max: &DocumentField{Type: DocumentFieldTypeNull}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := c.Query("score", tt.params); !errors.Is(err, ErrInvalidQueryBound) {
+				t.Errorf("Query() = %v, %v, wantErr %v", got, err, ErrInvalidQueryBound)
+			}
+		})
+	}
+}
+
+func TestCollection_QueryTimeRange(t *testing.T) {
+	config := &CollectionConfig{PrimaryKey: "id"}
+	c := &Collection{config: config, documents: map[string]Document{}}
+	if err := c.CreateIndex("created_at"); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	day := func(d int) DocumentField {
+		return DocumentField{Type: DocumentFieldTypeTime, Value: time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)}
+	}
+	for i, d := range []int{3, 1, 2} {
+		doc := Document{Fields: map[string]DocumentField{
+			"id":         {Type: DocumentFieldTypeString, Value: string(rune('a' + i))},
+			"created_at": day(d),
+		}}
+		if _, _, err := c.Put(doc); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	// Рядок із датою не потрапляє в діапазон за часом
+	if _, _, err := c.Put(Document{Fields: map[string]DocumentField{
+		"id":         {Type: DocumentFieldTypeString, Value: "s"},
+		"created_at": {Type: DocumentFieldTypeString, Value: "2024-01-02"},
+	}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	from, to := day(2), day(3)
+	got, err := c.Query("created_at", QueryParams{Min: &from, Max: &to, Desc: true})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	keys := make([]string, 0, len(got))
+	for _, doc := range got {
+		keys = append(keys, doc.Fields["id"].Value.(string))
+	}
+	if want := []string{"a", "c"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Query() got = %v, want %v", keys, want)
+	}
+}
+
+func TestCollection_CreateIndexWithOptions(t *testing.T) {
+	config := &CollectionConfig{PrimaryKey: "id"}
+	str := func(v string) DocumentField { return DocumentField{Type: DocumentFieldTypeString, Value: v} }
+	docs := map[string]Document{
+		"1": {Fields: map[string]DocumentField{"id": str("1"), "email": str("a@x")}},
+		"2": {Fields: map[string]DocumentField{"id": str("2"), "email": str("a@x")}},
+		"3": {Fields: map[string]DocumentField{"id": str("3"), "email": {Type: DocumentFieldTypeNull}}},
+		"4": {Fields: map[string]DocumentField{"id": str("4"), "email": {Type: DocumentFieldTypeNull}}},
+	}
+
+	tests := []struct {
+		name    string
+		opts    IndexOptions
+		wantErr error
+	}{
+		{name: "Non-unique index over duplicates", opts: IndexOptions{Fields: []string{"email"}}},
+		{name: "Unique index over duplicates", opts: IndexOptions{Fields: []string{"email"}, Unique: true}, wantErr: ErrUniqueViolation},
+		{name: "No fields", opts: IndexOptions{Name: "empty"}, wantErr: ErrInvalidFieldType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collection{config: config, documents: docs}
+			if err := c.CreateIndexWithOptions(tt.opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateIndexWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Кілька null-значень не порушують унікальність
+	c := &Collection{config: config, documents: map[string]Document{"3": docs["3"], "4": docs["4"]}}
+	if err := c.CreateIndexWithOptions(IndexOptions{Fields: []string{"email"}, Unique: true}); err != nil {
+		t.Errorf("CreateIndexWithOptions() error = %v, want nil for null values", err)
+	}
+}
+
+func TestCollection_PutIfVersion(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	doc := func(name string) Document {
+		return Document{Fields: map[string]DocumentField{
+			"id":   {Type: DocumentFieldTypeString, Value: "1"},
+			"name": {Type: DocumentFieldTypeString, Value: name},
+		}}
+	}
+
+	_, v1, err := c.PutIfVersion(doc("Alice"), 0)
+	if err != nil {
+		t.Fatalf("PutIfVersion() insert error = %v", err)
+	}
+	if _, _, err := c.PutIfVersion(doc("Mallory"), 0); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("PutIfVersion() insert over existing error = %v, wantErr %v", err, ErrVersionConflict)
+	}
+
+	got, _ := c.Get("1")
+	if got.Version != v1 {
+		t.Errorf("Get() version = %d, want %d", got.Version, v1)
+	}
+
+	_, v2, err := c.PutIfVersion(doc("Bob"), v1)
+	if err != nil {
+		t.Fatalf("PutIfVersion() update error = %v", err)
+	}
+	if v2 <= v1 {
+		t.Errorf("PutIfVersion() version = %d, want > %d", v2, v1)
+	}
+	// Запис зі старою версією - конфлікт, документ не змінюється
+	if _, _, err := c.PutIfVersion(doc("Carol"), v1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("PutIfVersion() stale error = %v, wantErr %v", err, ErrVersionConflict)
+	}
+	got, _ = c.Get("1")
+	if got.Fields["name"].Value != "Bob" || got.Version != v2 {
+		t.Errorf("Get() got = %v, want Bob at version %d", got, v2)
+	}
+
+	if err := c.DeleteIfVersion(v1, "1"); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("DeleteIfVersion() stale error = %v, wantErr %v", err, ErrVersionConflict)
+	}
+	if err := c.DeleteIfVersion(v2, "1"); err != nil {
+		t.Errorf("DeleteIfVersion() error = %v", err)
+	}
+	// Версії не повторюються після видалення
+	_, v3, err := c.PutIfVersion(doc("Dave"), 0)
+	if err != nil {
+		t.Fatalf("PutIfVersion() re-insert error = %v", err)
+	}
+	if v3 <= v2 {
+		t.Errorf("PutIfVersion() version after delete = %d, want > %d", v3, v2)
+	}
+	// Put без перевірки теж повертає нову версію
+	_, v4, err := c.Put(doc("Eve"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got, _ = c.Get("1"); v4 <= v3 || got.Version != v4 {
+		t.Errorf("Put() version = %d, stored %d, want > %d", v4, got.Version, v3)
+	}
+}
+
+func TestCollection_PutIfVersionConcurrent(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	counter := func(n int64) Document {
+		return Document{Fields: map[string]DocumentField{
+			"id": {Type: DocumentFieldTypeString, Value: "counter"},
+			"n":  {Type: DocumentFieldTypeInteger, Value: n},
+		}}
+	}
+	if _, _, err := c.Put(counter(0)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	const workers, increments = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				for {
+					cur, err := c.Get("counter")
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					_, _, err = c.PutIfVersion(counter(cur.Fields["n"].Value.(int64)+1), cur.Version)
+					if err == nil {
+						break
+					}
+					if !errors.Is(err, ErrVersionConflict) {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, _ := c.Get("counter")
+	if n := got.Fields["n"].Value.(int64); n != workers*increments {
+		t.Errorf("counter = %d, want %d", n, workers*increments)
+	}
+}
+
+func TestCollection_InsertReplace(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    func(c *Collection, doc Document) (string, error)
+		id       string
+		wantErr  error
+		wantName string // значення name документа "1" після запису
+	}{
+		{name: "Insert new", write: (*Collection).Insert, id: "2", wantName: "Alice"},
+		{name: "Insert existing", write: (*Collection).Insert, id: "1", wantErr: ErrDocumentAlreadyExists, wantName: "Alice"},
+		{name: "Replace existing", write: (*Collection).Replace, id: "1", wantName: "Bob"},
+		{name: "Replace missing", write: (*Collection).Replace, id: "2", wantErr: ErrDocumentNotFound, wantName: "Alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+			if _, _, err := c.Put(txDoc("1", map[string]any{"name": "Alice"})); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			key, err := tt.write(c, txDoc(tt.id, map[string]any{"name": "Bob"}))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("write error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && key != tt.id {
+				t.Errorf("write key = %v, want %v", key, tt.id)
+			}
+			got, _ := c.Get("1")
+			if got.Fields["name"].Value != tt.wantName {
+				t.Errorf("Get() name = %v, want %v", got.Fields["name"].Value, tt.wantName)
+			}
+			wantDocs := 1
+			if tt.id == "2" && tt.wantErr == nil {
+				wantDocs = 2
+			}
+			if c.NumDocuments() != wantDocs {
+				t.Errorf("NumDocuments() = %d, want %d", c.NumDocuments(), wantDocs)
+			}
+		})
+	}
+}
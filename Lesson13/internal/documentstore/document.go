@@ -1,5 +1,13 @@
 package documentstore
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
 type DocumentFieldType string
 
 const (
@@ -8,13 +16,147 @@ const (
 	DocumentFieldTypeBool   DocumentFieldType = "bool"
 	DocumentFieldTypeArray  DocumentFieldType = "array"
 	DocumentFieldTypeObject DocumentFieldType = "object"
+	DocumentFieldTypeNull   DocumentFieldType = "null"
+
+	// DocumentFieldTypeInteger зберігає ціле число як int64
+	DocumentFieldTypeInteger DocumentFieldType = "integer"
+	// DocumentFieldTypeDecimal зберігає число з точним десятковим записом як json.Number
+	DocumentFieldTypeDecimal DocumentFieldType = "decimal"
+	// DocumentFieldTypeTime зберігає time.Time; у дампі - RFC3339 з наносекундами
+	DocumentFieldTypeTime DocumentFieldType = "time"
+	// DocumentFieldTypeBinary зберігає []byte; у дампі - base64
+	DocumentFieldTypeBinary DocumentFieldType = "binary"
 )
 
+// DocumentField - типізоване значення. Для object Value містить map[string]DocumentField,
+// для array - []DocumentField, тож тип відомий на будь-якій глибині.
 type DocumentField struct {
-	Type  DocumentFieldType
-	Value interface{}
+	Type  DocumentFieldType `json:"type"`
+	Value interface{}       `json:"value"`
 }
 
 type Document struct {
-	Fields map[string]DocumentField
+	Fields map[string]DocumentField `json:"fields"`
+	// Version - ревізія документа, яку призначає Collection при кожному записі.
+	// 0 означає, що документ ще не записувався (або відновлений зі старого дампа).
+	Version uint64 `json:"version,omitempty"`
+	// ExpiresAt - термін дії документа, який Collection обчислює при кожному записі
+	// з TTL або ExpiresAtField конфігурації; nil - документ не старіє.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// UnmarshalJSON відновлює Value з урахуванням Type, щоб цілі та десяткові
+// числа не перетворювались на float64 при читанні дампу.
+func (f *DocumentField) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type  DocumentFieldType `json:"type"`
+		Value json.RawMessage   `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	value, err := decodeFieldValue(raw.Type, raw.Value)
+	if err != nil {
+		return fmt.Errorf("field of type '%s': %w", raw.Type, err)
+	}
+	f.Type = raw.Type
+	f.Value = value
+	return nil
+}
+
+func decodeFieldValue(t DocumentFieldType, raw json.RawMessage) (any, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	switch t {
+	case DocumentFieldTypeInteger:
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, err
+		}
+		return strconv.ParseInt(n.String(), 10, 64)
+	case DocumentFieldTypeDecimal:
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case DocumentFieldTypeTime:
+		var str string
+		if err := json.Unmarshal(raw, &str); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, str)
+	case DocumentFieldTypeBinary:
+		var b []byte
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	case DocumentFieldTypeArray:
+		var items []DocumentField
+		if err := json.Unmarshal(raw, &items); err == nil && typedItems(items) {
+			return items, nil
+		}
+		return decodeLegacyNested(raw)
+	case DocumentFieldTypeObject:
+		var fields map[string]DocumentField
+		if err := json.Unmarshal(raw, &fields); err == nil && typedFields(fields) {
+			return fields, nil
+		}
+		return decodeLegacyNested(raw)
+	default:
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+}
+
+// decodeLegacyNested читає вкладені значення зі старих дампів,
+// де масиви та об'єкти зберігались як звичайний JSON без типів
+func decodeLegacyNested(raw json.RawMessage) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	field, err := toDocumentField(v)
+	if err != nil {
+		return nil, err
+	}
+	return field.Value, nil
+}
+
+// typedFields і typedItems відрізняють новий формат від старого: старий
+// вкладений об'єкт (наприклад {"address":{"city":"X"}}) теж демаршалиться
+// у DocumentField, але з порожнім типом і без значення
+func typedFields(fields map[string]DocumentField) bool {
+	for _, field := range fields {
+		if !knownFieldType(field.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+func typedItems(items []DocumentField) bool {
+	for _, item := range items {
+		if !knownFieldType(item.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+func knownFieldType(t DocumentFieldType) bool {
+	switch t {
+	case DocumentFieldTypeString, DocumentFieldTypeNumber, DocumentFieldTypeBool,
+		DocumentFieldTypeArray, DocumentFieldTypeObject, DocumentFieldTypeNull,
+		DocumentFieldTypeInteger, DocumentFieldTypeDecimal, DocumentFieldTypeTime, DocumentFieldTypeBinary:
+		return true
+	}
+	return false
 }
@@ -3,18 +3,50 @@ package documentstore
 import (
 	"encoding/json"
 	"log/slog"
+	"maps"
 	"os"
 )
 
+// storeDump - тимчасова структура для дампа Store
+type storeDump struct {
+	Collections map[string]*collectionDump `json:"collections"`
+}
+
+// collectionDump - тимчасова структура для дампа Collection
+type collectionDump struct {
+	Config    *CollectionConfig   `json:"config"`
+	Documents map[string]Document `json:"documents"`
+	Sequence  uint64              `json:"sequence,omitempty"`  // останнє значення KeyGenerationSequence
+	Revision  uint64              `json:"revision,omitempty"`  // остання видана версія документа
+	ChangeSeq uint64              `json:"changeSeq,omitempty"` // номер останньої події Watch; історія подій не зберігається
+}
+
 // Dump повертає дамп (JSON) усього Store: колекцій та документів
 func (s *Store) Dump() ([]byte, error) {
-	dump, err := json.Marshal(s)
+	dump := &storeDump{
+		Collections: make(map[string]*collectionDump),
+	}
+	s.mu.RLock()
+	for name, col := range s.collections {
+		col.mu.RLock()
+		dump.Collections[name] = &collectionDump{
+			Config:    col.config,
+			Documents: maps.Clone(col.documents),
+			Sequence:  col.keys.sequence,
+			Revision:  col.revision,
+			ChangeSeq: col.changeSeq,
+		}
+		col.mu.RUnlock()
+	}
+	s.mu.RUnlock()
+
+	data, err := json.Marshal(dump)
 	if err != nil {
 		slog.Error("STORE DUMP FAILED", slog.Any("error", err), slog.String("message", "Помилка маршалінгу JSON"))
 		return nil, err
 	}
-	slog.Debug("STORE DUMPED", slog.String("message", "Створено дамп сховища"), slog.String("dump_json", string(dump)))
-	return dump, nil
+	slog.Debug("STORE DUMPED", slog.String("message", "Створено дамп сховища"), slog.String("dump_json", string(data)))
+	return data, nil
 }
 
 // DumpToFile зберігає дамп Store у файл
@@ -35,15 +67,38 @@ func (s *Store) DumpToFile(filename string) error {
 
 // NewStoreFromDump створює новий Store із JSON-дампу
 func NewStoreFromDump(dump []byte) (*Store, error) {
-	var store Store
-	err := json.Unmarshal(dump, &store)
+	var storeDumpData storeDump
+	err := json.Unmarshal(dump, &storeDumpData)
 	if err != nil {
 		slog.Error("STORE RESTORE FAILED", slog.Any("error", err), slog.String("dump_json", string(dump)), slog.String("message", "Помилка демаршалінгу JSON"))
 		return nil, err
 	}
+
+	store := NewStore()
+	for name, colDump := range storeDumpData.Collections {
+		// Дамп міг бути змінений або створений старою версією - перевіряємо, як CreateCollection
+		if message, err := colDump.Config.check(); err != nil {
+			slog.Error("STORE RESTORE FAILED", slog.String("collection", name), slog.Any("error", err), slog.String("message", message+" у дампі"))
+			return nil, err
+		}
+		col := &Collection{
+			config:    colDump.Config,
+			documents: colDump.Documents,
+			keys:      keyGenerator{sequence: colDump.Sequence},
+			revision:  colDump.Revision,
+			changeSeq: colDump.ChangeSeq,
+		}
+		// Версії не повинні повторюватися, навіть якщо revision у дампі відсутній
+		for _, doc := range col.documents {
+			col.revision = max(col.revision, doc.Version)
+		}
+		col.rebuildCap()
+		store.collections[name] = col
+	}
+
 	slog.Info("STORE RESTORED FROM DUMP", slog.String("message", "Сховище успішно відновлено з дампу"))
 	slog.Debug("STORE RESTORED FROM DUMP", slog.Any("restored_store", store))
-	return &store, nil
+	return store, nil
 }
 
 // NewStoreFromFile читає дамп із файлу та відновлює Store
@@ -2,9 +2,11 @@ package documentstore
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewStoreFromDump(t *testing.T) {
@@ -293,3 +295,117 @@ func TestStore_DumpToFile(t *testing.T) {
 		})
 	}
 }
+
+func TestStore_DumpTypedValuesRoundTrip(t *testing.T) {
+	config := &CollectionConfig{PrimaryKey: "id"}
+	doc := Document{Fields: map[string]DocumentField{
+		"id":         {Type: DocumentFieldTypeString, Value: "1"},
+		"deleted_at": {Type: DocumentFieldTypeNull, Value: nil},
+		"account":    {Type: DocumentFieldTypeInteger, Value: int64(9007199254740993)},
+		"balance":    {Type: DocumentFieldTypeDecimal, Value: json.Number("12345678901234567.89")},
+		"created_at": {Type: DocumentFieldTypeTime, Value: time.Date(2024, 5, 1, 10, 30, 0, 123456789, time.UTC)},
+		"avatar":     {Type: DocumentFieldTypeBinary, Value: []byte{0xde, 0xad, 0xbe, 0xef}},
+	}}
+	s := &Store{collections: map[string]*Collection{"users": {config: config, documents: map[string]Document{"1": doc}}}}
+
+	dump, err := s.Dump()
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	got, err := NewStoreFromDump(dump)
+	if err != nil {
+		t.Fatalf("NewStoreFromDump() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("NewStoreFromDump() got = %v, want %v", got, s)
+	}
+}
+
+func TestStore_DumpKeepsVersions(t *testing.T) {
+	s := NewStore()
+	if err := s.CreateCollection("users", &CollectionConfig{PrimaryKey: "id"}); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	users, _ := s.GetCollection("users")
+	for _, id := range []string{"1", "2"} {
+		if _, _, err := users.Put(Document{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: id}}}); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	if err := users.Delete("2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	before, _ := users.Get("1")
+
+	dump, err := s.Dump()
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	restored, err := NewStoreFromDump(dump)
+	if err != nil {
+		t.Fatalf("NewStoreFromDump() error = %v", err)
+	}
+	users, _ = restored.GetCollection("users")
+	after, _ := users.Get("1")
+	if after.Version != before.Version {
+		t.Errorf("restored version = %d, want %d", after.Version, before.Version)
+	}
+	_, version, err := users.PutIfVersion(Document{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "2"}}}, 0)
+	if err != nil {
+		t.Fatalf("PutIfVersion() error = %v", err)
+	}
+	if version != 3 {
+		t.Errorf("PutIfVersion() version = %d, want 3 (revision survives the dump)", version)
+	}
+}
+
+func TestNewStoreFromDump_LegacyNested(t *testing.T) {
+	dump := []byte(`{"collections":{"users":{"config":{"primaryKey":"id"},"documents":{"1":{"fields":{
+		"id":{"type":"string","value":"1"},
+		"profile":{"type":"object","value":{"address":{"city":"Kyiv"},"tags":["a"]}},
+		"history":{"type":"array","value":[{"city":"Lviv"}]}
+	}}}}}}`)
+	store, err := NewStoreFromDump(dump)
+	if err != nil {
+		t.Fatalf("NewStoreFromDump() error = %v", err)
+	}
+	users, err := store.GetCollection("users")
+	if err != nil {
+		t.Fatalf("GetCollection() error = %v", err)
+	}
+	doc, err := users.Get("1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	want := map[string]any{
+		"id":      "1",
+		"profile": map[string]any{"address": map[string]any{"city": "Kyiv"}, "tags": []any{"a"}},
+		"history": []any{map[string]any{"city": "Lviv"}},
+	}
+	if got := doc.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMap() got = %v, want %v", got, want)
+	}
+}
+
+func TestNewStoreFromDump_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr error
+	}{
+		{name: "Both primary key forms", config: `{"primaryKey":"id","primaryKeys":["a","b"]}`, wantErr: ErrInvalidPrimaryKey},
+		{name: "Negative cap", config: `{"primaryKey":"id","maxDocuments":-1}`, wantErr: ErrInvalidCap},
+		{name: "Negative TTL", config: `{"primaryKey":"id","ttl":-1}`, wantErr: ErrInvalidExpiry},
+		{name: "Expiry in primary key", config: `{"primaryKey":"id","expiresAtField":"id"}`, wantErr: ErrInvalidExpiry},
+		{name: "Unknown key generation", config: `{"primaryKey":"id","keyGeneration":"random"}`, wantErr: ErrInvalidKeyGeneration},
+		{name: "Missing config", config: `null`, wantErr: ErrInvalidPrimaryKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dump := []byte(`{"collections":{"c":{"config":` + tt.config + `,"documents":{}}}}`)
+			if _, err := NewStoreFromDump(dump); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewStoreFromDump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
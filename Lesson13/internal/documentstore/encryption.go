@@ -0,0 +1,236 @@
+package documentstore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrInvalidKeySize   = errors.New("encryption key must be 16, 24 or 32 bytes long")
+	ErrInvalidKey       = errors.New("encryption key is not valid base64")
+	ErrInvalidKeyID     = errors.New("invalid encryption key id")
+	ErrKeyNotFound      = errors.New("encryption key not found")
+	ErrNotEncryptedDump = errors.New("dump is not encrypted or has a corrupted header")
+	ErrDecryptionFailed = errors.New("failed to decrypt dump: wrong key or corrupted data")
+)
+
+// encryptedDumpMagic - сигнатура на початку зашифрованого дампу.
+// Формат файлу: magic | довжина keyID (1 байт) | keyID | nonce | шифротекст AES-GCM.
+// Заголовок (magic + keyID) передається як additional data, тож підміна keyID виявляється при розшифруванні.
+var encryptedDumpMagic = []byte("GORDENC1")
+
+// KeyProvider постачає ключі для шифрування дампів.
+// Ідентифікатор ключа зберігається у заголовку дампу, тому після ротації
+// старі дампи залишаються читабельними, поки провайдер знає їхній ключ.
+type KeyProvider interface {
+	// CurrentKey повертає ключ, яким шифруються нові дампи.
+	CurrentKey() (keyID string, key []byte, err error)
+	// Key повертає ключ за ідентифікатором із заголовка дампу.
+	Key(keyID string) ([]byte, error)
+}
+
+// EnvKeyProvider читає ключі зі змінних середовища:
+// <Prefix>_KEY_ID містить ідентифікатор поточного ключа,
+// <Prefix>_KEY_<id> - сам ключ у base64.
+type EnvKeyProvider struct {
+	Prefix string
+}
+
+func (p EnvKeyProvider) CurrentKey() (string, []byte, error) {
+	keyID := os.Getenv(p.Prefix + "_KEY_ID")
+	if keyID == "" {
+		return "", nil, fmt.Errorf("%w: variable '%s_KEY_ID' is not set", ErrKeyNotFound, p.Prefix)
+	}
+	key, err := p.Key(keyID)
+	if err != nil {
+		return "", nil, err
+	}
+	return keyID, key, nil
+}
+
+func (p EnvKeyProvider) Key(keyID string) ([]byte, error) {
+	if err := validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	name := p.Prefix + "_KEY_" + keyID
+	encoded, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: variable '%s' is not set", ErrKeyNotFound, name)
+	}
+	return decodeKey(encoded)
+}
+
+// FileKeyProvider читає ключі з файлів <Dir>/<id>.key, що містять ключ у base64.
+// CurrentID - ідентифікатор ключа для нових дампів.
+type FileKeyProvider struct {
+	Dir       string
+	CurrentID string
+}
+
+func (p FileKeyProvider) CurrentKey() (string, []byte, error) {
+	key, err := p.Key(p.CurrentID)
+	if err != nil {
+		return "", nil, err
+	}
+	return p.CurrentID, key, nil
+}
+
+func (p FileKeyProvider) Key(keyID string) ([]byte, error) {
+	if err := validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(p.Dir, keyID+".key")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: file '%s' does not exist", ErrKeyNotFound, path)
+		}
+		return nil, err
+	}
+	return decodeKey(string(data))
+}
+
+func validateKeyID(keyID string) error {
+	if keyID == "" || len(keyID) > 255 || strings.ContainsAny(keyID, `/\`) || keyID == "." || keyID == ".." {
+		return fmt.Errorf("%w: '%s'", ErrInvalidKeyID, keyID)
+	}
+	return nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, ErrInvalidKeySize
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeySize, err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptDump шифрує дамп поточним ключем провайдера (AES-GCM)
+func EncryptDump(dump []byte, kp KeyProvider) ([]byte, error) {
+	keyID, key, err := kp.CurrentKey()
+	if err != nil {
+		slog.Error("DUMP ENCRYPT FAILED", slog.Any("error", err), slog.String("message", "Не вдалося отримати поточний ключ"))
+		return nil, err
+	}
+	if err := validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(encryptedDumpMagic)+1+len(keyID))
+	header = append(header, encryptedDumpMagic...)
+	header = append(header, byte(len(keyID)))
+	header = append(header, keyID...)
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(dump)+gcm.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	out = gcm.Seal(out, nonce, dump, header)
+	slog.Debug("DUMP ENCRYPTED", slog.String("keyID", keyID), slog.String("message", fmt.Sprintf("Дамп зашифровано ключем '%s'", keyID)))
+	return out, nil
+}
+
+// DecryptDump розшифровує дамп ключем, ідентифікатор якого записано в заголовку
+func DecryptDump(data []byte, kp KeyProvider) ([]byte, error) {
+	if !bytes.HasPrefix(data, encryptedDumpMagic) || len(data) < len(encryptedDumpMagic)+1 {
+		return nil, ErrNotEncryptedDump
+	}
+	idLen := int(data[len(encryptedDumpMagic)])
+	headerLen := len(encryptedDumpMagic) + 1 + idLen
+	if len(data) < headerLen {
+		return nil, ErrNotEncryptedDump
+	}
+	header := data[:headerLen]
+	keyID := string(header[len(encryptedDumpMagic)+1:])
+
+	key, err := kp.Key(keyID)
+	if err != nil {
+		slog.Error("DUMP DECRYPT FAILED", slog.String("keyID", keyID), slog.Any("error", err), slog.String("message", fmt.Sprintf("Ключ '%s' недоступний", keyID)))
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := data[headerLen:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrNotEncryptedDump
+	}
+	nonce, ciphertext := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+
+	plain, err := gcm.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		slog.Error("DUMP DECRYPT FAILED", slog.String("keyID", keyID), slog.String("message", fmt.Sprintf("Не вдалося розшифрувати дамп ключем '%s'", keyID)))
+		return nil, fmt.Errorf("%w (key id '%s')", ErrDecryptionFailed, keyID)
+	}
+	slog.Debug("DUMP DECRYPTED", slog.String("keyID", keyID), slog.String("message", fmt.Sprintf("Дамп розшифровано ключем '%s'", keyID)))
+	return plain, nil
+}
+
+// DumpToEncryptedFile зберігає зашифрований дамп Store у файл
+func (s *Store) DumpToEncryptedFile(filename string, kp KeyProvider) error {
+	data, err := s.Dump()
+	if err != nil {
+		return err
+	}
+	encrypted, err := EncryptDump(data, kp)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filename, encrypted, 0600)
+	if err != nil {
+		slog.Error("STORE DUMP TO FILE FAILED", slog.String("filename", filename), slog.Any("error", err), slog.String("message", "Помилка запису у файл"))
+		return err
+	}
+	slog.Info("STORE DUMPED TO ENCRYPTED FILE", slog.String("filename", filename), slog.String("message", "Зашифрований дамп сховища збережено у файл"))
+	return nil
+}
+
+// NewStoreFromEncryptedFile читає зашифрований дамп із файлу та відновлює Store
+func NewStoreFromEncryptedFile(filename string, kp KeyProvider) (*Store, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		slog.Error("STORE RESTORE FROM FILE FAILED", slog.String("filename", filename), slog.Any("error", err), slog.String("message", "Помилка читання файлу"))
+		return nil, err
+	}
+	plain, err := DecryptDump(data, kp)
+	if err != nil {
+		return nil, err
+	}
+	store, err := NewStoreFromDump(plain)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("STORE RESTORED FROM ENCRYPTED FILE", slog.String("filename", filename), slog.String("message", "Сховище успішно відновлено із зашифрованого файлу"))
+	return store, nil
+}
@@ -0,0 +1,161 @@
+package documentstore
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type staticKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+func (p staticKeyProvider) CurrentKey() (string, []byte, error) {
+	key, err := p.Key(p.current)
+	return p.current, key, err
+}
+
+func (p staticKeyProvider) Key(keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+func testKey(b byte) []byte {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = b
+	}
+	return key
+}
+
+func TestEncryptDecryptDump(t *testing.T) {
+	plain := []byte(`{"collections":{}}`)
+	v1 := staticKeyProvider{current: "v1", keys: map[string][]byte{"v1": testKey(1)}}
+	encrypted, err := EncryptDump(plain, v1)
+	if err != nil {
+		t.Fatalf("EncryptDump() error = %v", err)
+	}
+
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name    string
+		data    []byte
+		kp      KeyProvider
+		want    []byte
+		wantErr error
+	}{
+		{
+			name: "Same key",
+			data: encrypted,
+			kp:   v1,
+			want: plain,
+		},
+		{
+			name: "Rotated provider still knows old key",
+			data: encrypted,
+			kp:   staticKeyProvider{current: "v2", keys: map[string][]byte{"v1": testKey(1), "v2": testKey(2)}},
+			want: plain,
+		},
+		{
+			name:    "Wrong key",
+			data:    encrypted,
+			kp:      staticKeyProvider{current: "v1", keys: map[string][]byte{"v1": testKey(9)}},
+			wantErr: ErrDecryptionFailed,
+		},
+		{
+			name:    "Unknown key id",
+			data:    encrypted,
+			kp:      staticKeyProvider{current: "v2", keys: map[string][]byte{"v2": testKey(2)}},
+			wantErr: ErrKeyNotFound,
+		},
+		{
+			name:    "Tampered ciphertext",
+			data:    tampered,
+			kp:      v1,
+			wantErr: ErrDecryptionFailed,
+		},
+		{
+			name:    "Plain dump",
+			data:    plain,
+			kp:      v1,
+			wantErr: ErrNotEncryptedDump,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecryptDump(tt.data, tt.kp)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecryptDump() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecryptDump() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_DumpToEncryptedFile(t *testing.T) {
+	config := &CollectionConfig{PrimaryKey: "id"}
+	doc := Document{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "1"}, "email": {Type: DocumentFieldTypeString, Value: "alice@example.com"}}}
+	s := &Store{collections: map[string]*Collection{"users": {config: config, documents: map[string]Document{"1": doc}}}}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "k1.key"), []byte(base64.StdEncoding.EncodeToString(testKey(7))+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	kp := FileKeyProvider{Dir: dir, CurrentID: "k1"}
+	filename := filepath.Join(dir, "dump.enc")
+
+	if err := s.DumpToEncryptedFile(filename, kp); err != nil {
+		t.Fatalf("DumpToEncryptedFile() error = %v", err)
+	}
+	if _, err := NewStoreFromFile(filename); err == nil {
+		t.Errorf("NewStoreFromFile() read an encrypted dump as plain JSON")
+	}
+
+	got, err := NewStoreFromEncryptedFile(filename, kp)
+	if err != nil {
+		t.Fatalf("NewStoreFromEncryptedFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("NewStoreFromEncryptedFile() got = %v, want %v", got, s)
+	}
+}
+
+func TestEnvKeyProvider(t *testing.T) {
+	t.Setenv("GORD_KEY_ID", "2025")
+	t.Setenv("GORD_KEY_2025", base64.StdEncoding.EncodeToString(testKey(3)))
+	t.Setenv("GORD_KEY_SHORT", base64.StdEncoding.EncodeToString([]byte("short")))
+	t.Setenv("GORD_KEY_BROKEN", "not base64!")
+
+	kp := EnvKeyProvider{Prefix: "GORD"}
+	keyID, key, err := kp.CurrentKey()
+	if err != nil {
+		t.Fatalf("CurrentKey() error = %v", err)
+	}
+	if keyID != "2025" || !reflect.DeepEqual(key, testKey(3)) {
+		t.Errorf("CurrentKey() got = (%s, %v)", keyID, key)
+	}
+	if _, err := kp.Key("SHORT"); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("Key() error = %v, wantErr %v", err, ErrInvalidKeySize)
+	}
+	if _, err := kp.Key("BROKEN"); !errors.Is(err, ErrInvalidKey) || errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("Key() error = %v, wantErr %v", err, ErrInvalidKey)
+	}
+	if _, err := kp.Key("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Key() error = %v, wantErr %v", err, ErrKeyNotFound)
+	}
+	if _, err := (FileKeyProvider{Dir: t.TempDir()}).Key("../etc"); !errors.Is(err, ErrInvalidKeyID) {
+		t.Errorf("Key() error = %v, wantErr %v", err, ErrInvalidKeyID)
+	}
+}
@@ -0,0 +1,114 @@
+package documentstore
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+)
+
+var ErrHookRejected = errors.New("write rejected by hook")
+
+// BeforeHook викликається перед записом або видаленням документа, коли вбудовані
+// перевірки (ключ, схема, режим запису) вже пройдено. Для запису хук може змінити
+// ev.After - результат ще раз перевіряється схемою, а ключ змінювати не можна.
+// Помилка скасовує запис і повертається викликачу обгорнутою в ErrHookRejected.
+//
+// Хук виконується під блокуванням колекції, тож не повинен звертатися до неї.
+type BeforeHook func(ev *ChangeEvent) error
+
+// AfterHook викликається після застосування зміни (Seq уже призначено), поза
+// блокуванням колекції - хук може писати в будь-які колекції. Помилка лише
+// журналюється: зміну вже застосовано, а решта хуків однаково викликається.
+type AfterHook func(ev ChangeEvent) error
+
+// AddBeforeHook реєструє before-хук; хуки викликаються в порядку реєстрації,
+// перший, що повернув помилку, зупиняє решту
+func (c *Collection) AddBeforeHook(h BeforeHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.beforeHooks = append(c.beforeHooks, h)
+}
+
+// AddAfterHook реєструє after-хук; хуки викликаються в порядку реєстрації,
+// для подій - у порядку їх застосування в межах однієї операції
+func (c *Collection) AddAfterHook(h AfterHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.afterHooks = append(c.afterHooks, h)
+}
+
+// beforePutLocked запускає before-хуки для запису документа з ключем key.
+// Викликається під c.mu.Lock().
+func (c *Collection) beforePutLocked(op ChangeOp, key string, doc Document) (Document, error) {
+	if len(c.beforeHooks) == 0 {
+		return doc, nil
+	}
+	// Хуки змінюють копію, а не map, передану викликачем
+	doc.Fields = maps.Clone(doc.Fields)
+	ev := ChangeEvent{Op: op, Key: key, After: &doc}
+	if before, ok := c.liveLocked(key); ok {
+		ev.Before = &before
+	}
+	if err := c.runBeforeHooks(&ev); err != nil {
+		return doc, err
+	}
+
+	doc = *ev.After
+	if err := c.validate(doc); err != nil {
+		return doc, err
+	}
+	if newKey, err := c.documentKey(doc); err != nil || newKey != key {
+		return doc, fmt.Errorf("%w: hook changed primary key of '%s'", ErrHookRejected, key)
+	}
+	return doc, nil
+}
+
+// beforeDeleteLocked запускає before-хуки для видалення наявного документа.
+// Викликається під c.mu.Lock().
+func (c *Collection) beforeDeleteLocked(key string) error {
+	if len(c.beforeHooks) == 0 {
+		return nil
+	}
+	before, ok := c.liveLocked(key)
+	if !ok {
+		return nil // ErrDocumentNotFound поверне сама операція
+	}
+	return c.runBeforeHooks(&ChangeEvent{Op: ChangeDelete, Key: key, Before: &before})
+}
+
+func (c *Collection) runBeforeHooks(ev *ChangeEvent) error {
+	for _, h := range c.beforeHooks {
+		if err := h(ev); err != nil {
+			slog.Debug("BeforeHook: write rejected", slog.String("key", ev.Key), slog.String("op", string(ev.Op)), slog.Any("error", err))
+			return fmt.Errorf("%w: %w", ErrHookRejected, err)
+		}
+	}
+	return nil
+}
+
+// afterHooksLocked забирає події, що очікують after-хуків, разом зі знімком хуків.
+// Викликається під c.mu.Lock().
+func (c *Collection) afterHooksLocked() ([]ChangeEvent, []AfterHook) {
+	events := c.pendingAfter
+	c.pendingAfter = nil
+	return events, c.afterHooks
+}
+
+// unlockAndRunHooks знімає блокування колекції та викликає after-хуки для подій,
+// опублікованих поточним власником блокування
+func (c *Collection) unlockAndRunHooks() {
+	events, hooks := c.afterHooksLocked()
+	c.mu.Unlock()
+	runAfterHooks(events, hooks)
+}
+
+func runAfterHooks(events []ChangeEvent, hooks []AfterHook) {
+	for _, ev := range events {
+		for _, h := range hooks {
+			if err := h(ev); err != nil {
+				slog.Warn("AFTER HOOK FAILED", slog.String("key", ev.Key), slog.String("op", string(ev.Op)), slog.Uint64("seq", ev.Seq), slog.Any("error", err), slog.String("message", "Помилка after-хука; зміну вже застосовано"))
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package documentstore
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var errBanned = errors.New("banned domain")
+
+// normalizeEmail переводить email у нижній регістр і відхиляє домен banned.x
+func normalizeEmail(ev *ChangeEvent) error {
+	if ev.After == nil {
+		return nil
+	}
+	email, ok := ev.After.Fields["email"].Value.(string)
+	if !ok {
+		return nil
+	}
+	email = strings.ToLower(email)
+	if strings.HasSuffix(email, "@banned.x") {
+		return errBanned
+	}
+	ev.After.Fields["email"] = DocumentField{Type: DocumentFieldTypeString, Value: email}
+	return nil
+}
+
+func TestCollection_BeforeHooks(t *testing.T) {
+	tests := []struct {
+		name      string
+		write     func(c *Collection) error
+		wantErr   error
+		wantEmail string // email документа "1" після запису; "" - документа немає
+	}{
+		{
+			name:      "Put is normalized",
+			write:     func(c *Collection) error { _, _, err := c.Put(txDoc("1", map[string]any{"email": "A@X"})); return err },
+			wantEmail: "a@x",
+		},
+		{
+			name: "Put is vetoed",
+			write: func(c *Collection) error {
+				_, _, err := c.Put(txDoc("1", map[string]any{"email": "A@BANNED.X"}))
+				return err
+			},
+			wantErr: errBanned,
+		},
+		{
+			name: "Update is normalized",
+			write: func(c *Collection) error {
+				if _, _, err := c.Put(txDoc("1", map[string]any{"email": "a@x"})); err != nil {
+					return err
+				}
+				_, err := c.Update("1", UpdateOps{UpdateSet: {"email": "B@X"}})
+				return err
+			},
+			wantEmail: "b@x",
+		},
+		{
+			name: "Batch item is vetoed",
+			write: func(c *Collection) error {
+				results, err := c.PutMany([]Document{txDoc("1", map[string]any{"email": "C@X"}), txDoc("2", map[string]any{"email": "d@banned.x"})}, BatchBestEffort)
+				if err != nil {
+					return err
+				}
+				return results[1].Err
+			},
+			wantErr:   errBanned,
+			wantEmail: "c@x",
+		},
+		{
+			name: "Transaction is vetoed",
+			write: func(c *Collection) error {
+				s := NewStore()
+				s.collections["users"] = c
+				tx := s.Begin()
+				tx.Put("users", txDoc("1", map[string]any{"email": "a@x"}))
+				tx.Put("users", txDoc("2", map[string]any{"email": "b@banned.x"}))
+				return tx.Commit()
+			},
+			wantErr: errBanned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+			c.AddBeforeHook(normalizeEmail)
+			err := tt.write(c)
+			if tt.wantErr != nil && (!errors.Is(err, ErrHookRejected) || !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("write error = %v, wantErr %v and %v", err, ErrHookRejected, tt.wantErr)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			got, err := c.Get("1")
+			switch {
+			case tt.wantEmail == "" && err == nil:
+				t.Errorf("Get() got = %v, want no document", got)
+			case tt.wantEmail != "" && (err != nil || got.Fields["email"].Value != tt.wantEmail):
+				t.Errorf("Get() got = %v, %v, want email %s", got, err, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestCollection_BeforeHookOrderAndErrors(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	var calls []string
+	c.AddBeforeHook(func(ev *ChangeEvent) error {
+		calls = append(calls, "first:"+string(ev.Op))
+		if ev.Op == ChangeDelete {
+			return errors.New("protected")
+		}
+		return nil
+	})
+	c.AddBeforeHook(func(ev *ChangeEvent) error {
+		calls = append(calls, "second:"+string(ev.Op))
+		return nil
+	})
+	if _, _, err := c.Put(txDoc("1", nil)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// Конфлікт версій відхиляється до запуску хуків
+	if _, _, err := c.PutIfVersion(txDoc("1", nil), 99); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("PutIfVersion() error = %v, wantErr %v", err, ErrVersionConflict)
+	}
+	if err := c.DeleteIfVersion(99, "1"); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("DeleteIfVersion() error = %v, wantErr %v", err, ErrVersionConflict)
+	}
+	if err := c.Delete("1"); !errors.Is(err, ErrHookRejected) {
+		t.Errorf("Delete() error = %v, wantErr %v", err, ErrHookRejected)
+	}
+	if results, _ := c.DeleteMany([]any{"1"}, BatchBestEffort); !errors.Is(results[0].Err, ErrHookRejected) {
+		t.Errorf("DeleteMany() item error = %v, wantErr %v", results[0].Err, ErrHookRejected)
+	}
+	want := []string{"first:put", "second:put", "first:delete", "first:delete"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("hook calls = %v, want %v", calls, want)
+	}
+	if c.NumDocuments() != 1 {
+		t.Errorf("NumDocuments() = %d, want 1", c.NumDocuments())
+	}
+
+	keyChanger := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	keyChanger.AddBeforeHook(func(ev *ChangeEvent) error {
+		ev.After.Fields["id"] = DocumentField{Type: DocumentFieldTypeString, Value: "other"}
+		return nil
+	})
+	if _, _, err := keyChanger.Put(txDoc("1", nil)); !errors.Is(err, ErrHookRejected) {
+		t.Errorf("Put() with changed key error = %v, wantErr %v", err, ErrHookRejected)
+	}
+}
+
+func TestCollection_AfterHooks(t *testing.T) {
+	s := newTxStore(t, "orders", "stats")
+	orders, _ := s.GetCollection("orders")
+	stats, _ := s.GetCollection("stats")
+
+	var seqs []uint64
+	orders.AddAfterHook(func(ev ChangeEvent) error {
+		seqs = append(seqs, ev.Seq)
+		return errors.New("ignored")
+	})
+	// Хук пише в іншу колекцію та в ту саму - блокування вже знято
+	orders.AddAfterHook(func(ev ChangeEvent) error {
+		if ev.Key == "count" {
+			return nil
+		}
+		if _, err := stats.Update("orders", UpdateOps{UpdateInc: {"n": 1}}); errors.Is(err, ErrDocumentNotFound) {
+			if _, _, err := stats.Put(txDoc("orders", map[string]any{"n": 1})); err != nil {
+				return err
+			}
+		}
+		_, _, err := orders.Put(txDoc("count", map[string]any{"n": len(seqs)}))
+		return err
+	})
+
+	if _, _, err := orders.Put(txDoc("1", nil)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := orders.PutMany([]Document{txDoc("2", nil), txDoc("3", nil)}, BatchAllOrNothing); err != nil {
+		t.Fatalf("PutMany() error = %v", err)
+	}
+	tx := s.Begin()
+	tx.Delete("orders", "1")
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	got, _ := stats.Get("orders")
+	if n := got.Fields["n"].Value; n != int64(4) {
+		t.Errorf("stats n = %v, want 4", n)
+	}
+	// Кожна з 4 змін замовлень спричиняє ще один запис "count" з хука
+	if len(seqs) != 8 {
+		t.Errorf("after hook seqs = %v, want 8 events", seqs)
+	}
+}
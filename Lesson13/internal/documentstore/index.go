@@ -0,0 +1,262 @@
+package documentstore
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidQueryBound = errors.New("query bound cannot be compared with indexed values")
+
+// fields повертає поля, з яких складається ключ індексу
+func (idx *Index) fields() []string {
+	if len(idx.Fields) > 0 {
+		return idx.Fields
+	}
+	return []string{idx.FieldName}
+}
+
+// values обчислює ключ індексу для документа. Простий індекс пропускає документи
+// без поля; у складеному відсутні поля вважаються null, а документ пропускається,
+// лише якщо немає жодного з полів.
+func (idx *Index) values(doc Document) ([]DocumentField, bool) {
+	fields := idx.fields()
+	values := make([]DocumentField, len(fields))
+	present := false
+	for i, name := range fields {
+		field, ok := doc.Fields[name]
+		if !ok {
+			if len(fields) == 1 {
+				return nil, false
+			}
+			values[i] = DocumentField{Type: DocumentFieldTypeNull}
+			continue
+		}
+		if !indexable(field) {
+			return nil, false
+		}
+		values[i] = field
+		present = true
+	}
+	return values, present
+}
+
+// compareIndexKeys порівнює ключі індексу поелементно
+func compareIndexKeys(a, b []DocumentField) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIndexValues(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func allNull(values []DocumentField) bool {
+	for _, v := range values {
+		if v.Type != DocumentFieldTypeNull {
+			return false
+		}
+	}
+	return true
+}
+
+// indexable повідомляє, чи може значення поля потрапити до індексу
+func indexable(field DocumentField) bool {
+	switch field.Type {
+	case DocumentFieldTypeNull:
+		return true
+	case DocumentFieldTypeString:
+		_, ok := field.Value.(string)
+		return ok
+	case DocumentFieldTypeNumber, DocumentFieldTypeInteger, DocumentFieldTypeDecimal:
+		_, ok := numericRat(field.Value)
+		return ok
+	case DocumentFieldTypeBinary:
+		_, ok := field.Value.([]byte)
+		return ok
+	case DocumentFieldTypeTime:
+		_, ok := field.Value.(time.Time)
+		return ok
+	default:
+		return false
+	}
+}
+
+// indexTypeRank задає порядок типів в індексі: null, числа, рядки, бінарні дані, час.
+// Усі числові типи мають однаковий ранг і порівнюються між собою за значенням.
+func indexTypeRank(t DocumentFieldType) int {
+	switch t {
+	case DocumentFieldTypeNull:
+		return 0
+	case DocumentFieldTypeNumber, DocumentFieldTypeInteger, DocumentFieldTypeDecimal:
+		return 1
+	case DocumentFieldTypeString:
+		return 2
+	case DocumentFieldTypeBinary:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// compareIndexValues порівнює два індексовані значення (-1, 0, 1)
+func compareIndexValues(a, b DocumentField) int {
+	if ra, rb := indexTypeRank(a.Type), indexTypeRank(b.Type); ra != rb {
+		return cmp.Compare(ra, rb)
+	}
+	switch a.Type {
+	case DocumentFieldTypeString:
+		return strings.Compare(a.Value.(string), b.Value.(string))
+	case DocumentFieldTypeNumber, DocumentFieldTypeInteger, DocumentFieldTypeDecimal:
+		return compareNumbers(a.Value, b.Value)
+	case DocumentFieldTypeBinary:
+		return bytes.Compare(a.Value.([]byte), b.Value.([]byte))
+	case DocumentFieldTypeTime:
+		return a.Value.(time.Time).Compare(b.Value.(time.Time))
+	default:
+		return 0
+	}
+}
+
+// compareNumbers порівнює числа різних типів без втрати точності:
+// int64 з int64 та float64 з float64 напряму, решту - через big.Rat.
+func compareNumbers(a, b any) int {
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return cmp.Compare(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	ra, okA := numericRat(a)
+	rb, okB := numericRat(b)
+	if !okA || !okB {
+		return 0
+	}
+	return ra.Cmp(rb)
+}
+
+func numericRat(v any) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(n) == nil { // NaN або нескінченність
+			return nil, false
+		}
+		return r, true
+	case json.Number:
+		return new(big.Rat).SetString(n.String())
+	default:
+		return nil, false
+	}
+}
+
+// decimalRat приводить число до big.Rat за його десятковим записом: float
+// береться за найкоротшим записом, що його відтворює, тож float64 0.1
+// дорівнює decimal "0.1". Для обчислень і перевірок схеми, а не для індексів.
+func decimalRat(v any) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(n, 'g', -1, 64))
+	case float32:
+		return new(big.Rat).SetString(strconv.FormatFloat(float64(n), 'g', -1, 32))
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(n)), true
+	default:
+		return numericRat(v)
+	}
+}
+
+// lowerBound і upperBound повертають межі запиту: типізовані Min/Max
+// мають пріоритет над рядковими MinValue/MaxValue.
+func (p QueryParams) lowerBound() *DocumentField {
+	if p.Min != nil {
+		return p.Min
+	}
+	if p.MinValue != nil {
+		return &DocumentField{Type: DocumentFieldTypeString, Value: *p.MinValue}
+	}
+	return nil
+}
+
+func (p QueryParams) upperBound() *DocumentField {
+	if p.Max != nil {
+		return p.Max
+	}
+	if p.MaxValue != nil {
+		return &DocumentField{Type: DocumentFieldTypeString, Value: *p.MaxValue}
+	}
+	return nil
+}
+
+// normalize приводить типізовані межі Min/Max до вигляду, в якому значення
+// зберігаються в індексі: Go-числа (int, uint8, float32, ...) - до int64/float64.
+// Межа, тип якої не відповідає значенню або яку не можна індексувати, - помилка.
+func (p QueryParams) normalize() (QueryParams, error) {
+	var err error
+	if p.Min, err = normalizeBound(p.Min); err != nil {
+		return p, err
+	}
+	if p.Max, err = normalizeBound(p.Max); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func normalizeBound(bound *DocumentField) (*DocumentField, error) {
+	if bound == nil {
+		return nil, nil
+	}
+	field := *bound
+	if indexTypeRank(field.Type) == indexTypeRank(DocumentFieldTypeNumber) {
+		normalized, err := toDocumentField(field.Value)
+		if err != nil || indexTypeRank(normalized.Type) != indexTypeRank(field.Type) {
+			return nil, fmt.Errorf("%w: '%s' bound has value %v (%T)", ErrInvalidQueryBound, bound.Type, bound.Value, bound.Value)
+		}
+		field = normalized
+	}
+	if field.Type == DocumentFieldTypeNull || !indexable(field) {
+		return nil, fmt.Errorf("%w: '%s' bound has value %v (%T)", ErrInvalidQueryBound, bound.Type, bound.Value, bound.Value)
+	}
+	return &field, nil
+}
+
+// matches перевіряє, чи потрапляє значення в діапазон запиту.
+// Типізовані межі мають бути нормалізовані через normalize.
+// Межа діапазону обмежує і тип: числова межа відбирає лише числа, рядкова - лише рядки.
+// null-значення повертаються лише для запитів без нижньої межі.
+func (p QueryParams) matches(field DocumentField) bool {
+	if !indexable(field) {
+		return false
+	}
+	if min := p.lowerBound(); min != nil {
+		if field.Type == DocumentFieldTypeNull || indexTypeRank(field.Type) != indexTypeRank(min.Type) {
+			return false
+		}
+		if compareIndexValues(field, *min) < 0 {
+			return false
+		}
+	}
+	if max := p.upperBound(); max != nil && field.Type != DocumentFieldTypeNull {
+		if indexTypeRank(field.Type) != indexTypeRank(max.Type) {
+			return false
+		}
+		if compareIndexValues(field, *max) > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package documentstore
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidKeyGeneration = errors.New("invalid key generation strategy")
+
+// KeyGeneration - стратегія генерації первинного ключа, якщо документ його не містить
+type KeyGeneration string
+
+const (
+	KeyGenerationNone     KeyGeneration = ""         // ключ обов'язковий
+	KeyGenerationUUIDv4   KeyGeneration = "uuid4"    // випадковий UUID
+	KeyGenerationUUIDv7   KeyGeneration = "uuid7"    // UUID, що сортується за часом створення
+	KeyGenerationSequence KeyGeneration = "sequence" // 1, 2, 3, ...; лічильник зберігається в дампі
+)
+
+func (g KeyGeneration) check() error {
+	switch g {
+	case KeyGenerationNone, KeyGenerationUUIDv4, KeyGenerationUUIDv7, KeyGenerationSequence:
+		return nil
+	}
+	return fmt.Errorf("%w: '%s'", ErrInvalidKeyGeneration, g)
+}
+
+// keyGenerator тримає стан генерації ключів колекції
+type keyGenerator struct {
+	sequence uint64 // останнє видане значення послідовності
+	lastMs   int64  // мілісекунда останнього UUIDv7
+	counter  uint16 // 12-бітний лічильник UUIDv7 у межах однієї мілісекунди
+}
+
+// nextKey генерує ключ, якого ще немає серед exists
+func (g *keyGenerator) nextKey(strategy KeyGeneration, exists func(string) bool) (string, error) {
+	for {
+		var key string
+		var err error
+		switch strategy {
+		case KeyGenerationUUIDv4:
+			key, err = newUUIDv4()
+		case KeyGenerationUUIDv7:
+			key, err = g.newUUIDv7(time.Now())
+		case KeyGenerationSequence:
+			g.sequence++
+			key = strconv.FormatUint(g.sequence, 10)
+		default:
+			return "", fmt.Errorf("%w: '%s'", ErrInvalidKeyGeneration, strategy)
+		}
+		if err != nil {
+			return "", err
+		}
+		// Ключ міг бути заданий вручну раніше (актуально для sequence)
+		if !exists(key) {
+			return key, nil
+		}
+	}
+}
+
+func newUUIDv4() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	u[6] = u[6]&0x0f | 0x40 // версія 4
+	u[8] = u[8]&0x3f | 0x80 // варіант RFC 9562
+	return formatUUID(u), nil
+}
+
+// newUUIDv7 будує UUIDv7 (RFC 9562): 48 біт часу в мілісекундах, далі 12-бітний
+// лічильник (rand_a) і 62 випадкові біти. Лічильник гарантує зростання ключів,
+// згенерованих у межах однієї мілісекунди.
+func (g *keyGenerator) newUUIDv7(now time.Time) (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[6:]); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	ms := now.UnixMilli()
+	if ms > g.lastMs {
+		g.lastMs = ms
+		g.counter = binary.BigEndian.Uint16(u[6:8]) & 0x07ff // старший біт лишаємо під переповнення
+	} else {
+		// Той самий момент або годинник пішов назад - продовжуємо від попереднього значення
+		g.counter++
+		if g.counter > 0x0fff {
+			g.lastMs++
+			g.counter = 0
+		}
+	}
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(g.lastMs))
+	copy(u[0:6], ts[2:8])
+	binary.BigEndian.PutUint16(u[6:8], 0x7000|g.counter) // версія 7
+	u[8] = u[8]&0x3f | 0x80                              // варіант RFC 9562
+	return formatUUID(u), nil
+}
+
+func formatUUID(u [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
@@ -0,0 +1,133 @@
+package documentstore
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"testing"
+)
+
+func TestCollection_PutKeyGeneration(t *testing.T) {
+	name := DocumentField{Type: DocumentFieldTypeString, Value: "Alice"}
+
+	tests := []struct {
+		name     string
+		strategy KeyGeneration
+		key      *DocumentField // nil - поле відсутнє
+		wantKey  *regexp.Regexp
+		wantErr  bool
+	}{
+		{name: "No strategy, missing key", strategy: KeyGenerationNone, wantErr: true},
+		{name: "UUIDv4", strategy: KeyGenerationUUIDv4, wantKey: regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{name: "UUIDv7", strategy: KeyGenerationUUIDv7, wantKey: regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{name: "Sequence", strategy: KeyGenerationSequence, wantKey: regexp.MustCompile(`^1$`)},
+		{name: "Null key is generated", strategy: KeyGenerationSequence, key: &DocumentField{Type: DocumentFieldTypeNull}, wantKey: regexp.MustCompile(`^1$`)},
+		{name: "Empty key is generated", strategy: KeyGenerationSequence, key: &DocumentField{Type: DocumentFieldTypeString, Value: ""}, wantKey: regexp.MustCompile(`^1$`)},
+		{name: "Explicit key is kept", strategy: KeyGenerationUUIDv4, key: &DocumentField{Type: DocumentFieldTypeString, Value: "alice"}, wantKey: regexp.MustCompile(`^alice$`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collection{config: &CollectionConfig{PrimaryKey: "id", KeyGeneration: tt.strategy}}
+			doc := Document{Fields: map[string]DocumentField{"name": name}}
+			if tt.key != nil {
+				doc.Fields["id"] = *tt.key
+			}
+			key, _, err := c.Put(doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Put() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !tt.wantKey.MatchString(key) {
+				t.Errorf("Put() key = %s, want match %s", key, tt.wantKey)
+			}
+			stored, err := c.Get(key)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got := stored.Fields["id"].Value; got != key {
+				t.Errorf("stored key = %v, want %s", got, key)
+			}
+			if tt.key == nil {
+				if _, ok := doc.Fields["id"]; ok {
+					t.Errorf("Put() modified the caller's document")
+				}
+			}
+		})
+	}
+}
+
+func TestCollection_PutKeyGenerationOrder(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id", KeyGeneration: KeyGenerationUUIDv7}}
+	var keys []string
+	for i := 0; i < 1000; i++ {
+		key, _, err := c.Put(Document{Fields: map[string]DocumentField{}})
+		if err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		keys = append(keys, key)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Errorf("UUIDv7 keys are not sorted in creation order")
+	}
+	if len(c.documents) != len(keys) {
+		t.Errorf("got %d documents, want %d", len(c.documents), len(keys))
+	}
+}
+
+func TestCollection_PutSequenceSkipsUsedKeys(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id", KeyGeneration: KeyGenerationSequence}}
+	if _, _, err := c.Put(Document{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "1"}}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	key, _, err := c.Put(Document{Fields: map[string]DocumentField{}})
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if key != "2" {
+		t.Errorf("Put() key = %s, want 2", key)
+	}
+}
+
+func TestStore_SequenceSurvivesDump(t *testing.T) {
+	s := NewStore()
+	if err := s.CreateCollection("orders", &CollectionConfig{PrimaryKey: "id", KeyGeneration: KeyGenerationSequence}); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	orders, _ := s.GetCollection("orders")
+	for i := 0; i < 3; i++ {
+		if _, _, err := orders.Put(Document{Fields: map[string]DocumentField{}}); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	// Видалений останній ключ не повинен видаватися повторно
+	if err := orders.Delete("3"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	dump, err := s.Dump()
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	restored, err := NewStoreFromDump(dump)
+	if err != nil {
+		t.Fatalf("NewStoreFromDump() error = %v", err)
+	}
+	orders, _ = restored.GetCollection("orders")
+	key, _, err := orders.Put(Document{Fields: map[string]DocumentField{}})
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if key != "4" {
+		t.Errorf("Put() after restore key = %s, want 4", key)
+	}
+}
+
+func TestStore_CreateCollectionInvalidKeyGeneration(t *testing.T) {
+	s := NewStore()
+	err := s.CreateCollection("users", &CollectionConfig{PrimaryKey: "id", KeyGeneration: "ulid"})
+	if !errors.Is(err, ErrInvalidKeyGeneration) {
+		t.Errorf("CreateCollection() error = %v, wantErr %v", err, ErrInvalidKeyGeneration)
+	}
+}
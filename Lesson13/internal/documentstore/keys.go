@@ -0,0 +1,242 @@
+package documentstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var (
+	ErrInvalidPrimaryKey   = errors.New("invalid primary key definition")
+	ErrInvalidKeyComponent = errors.New("primary key component must be a non-empty string or a number")
+)
+
+// keyFields повертає поля первинного ключа в порядку компонентів
+func (cfg *CollectionConfig) keyFields() []string {
+	if len(cfg.PrimaryKeys) > 0 {
+		return cfg.PrimaryKeys
+	}
+	return []string{cfg.PrimaryKey}
+}
+
+// checkKeys перевіряє опис складеного ключа
+func (cfg *CollectionConfig) checkKeys() error {
+	if len(cfg.PrimaryKeys) == 0 {
+		return nil
+	}
+	if cfg.PrimaryKey != "" {
+		return fmt.Errorf("%w: set either primaryKey or primaryKeys", ErrInvalidPrimaryKey)
+	}
+	if cfg.KeyGeneration != KeyGenerationNone {
+		return fmt.Errorf("%w: keys cannot be generated for a composite primary key", ErrInvalidKeyGeneration)
+	}
+	seen := make(map[string]bool, len(cfg.PrimaryKeys))
+	for _, f := range cfg.PrimaryKeys {
+		if f == "" || seen[f] {
+			return fmt.Errorf("%w: empty or duplicate field '%s'", ErrInvalidPrimaryKey, f)
+		}
+		seen[f] = true
+	}
+	return nil
+}
+
+// documentKey обчислює внутрішній ключ документа.
+// Для одного поля це сам рядок; для складеного ключа - JSON-масив
+// канонічних значень компонентів, наприклад ["acc-1","2024-01-31"] або ["acc-1",42].
+func (c *Collection) documentKey(doc Document) (string, error) {
+	if len(c.config.PrimaryKeys) == 0 {
+		keyField, ok := doc.Fields[c.config.PrimaryKey]
+		if !ok {
+			return "", fmt.Errorf("missing '%s' field", c.config.PrimaryKey)
+		}
+		if keyField.Type != DocumentFieldTypeString {
+			return "", ErrInvalidKeyType
+		}
+		key, ok := keyField.Value.(string)
+		if !ok || key == "" {
+			return "", ErrEmptyKey
+		}
+		return key, nil
+	}
+
+	components := make([]DocumentField, len(c.config.PrimaryKeys))
+	for i, name := range c.config.PrimaryKeys {
+		field, ok := doc.Fields[name]
+		if !ok {
+			return "", fmt.Errorf("missing '%s' field", name)
+		}
+		components[i] = field
+	}
+	return encodeKey(components)
+}
+
+// lookupKey перетворює значення компонентів, передані в Get/Delete, на внутрішній ключ
+func (c *Collection) lookupKey(values []any) (string, error) {
+	if c.config == nil || len(c.config.PrimaryKeys) == 0 {
+		if len(values) != 1 {
+			return "", fmt.Errorf("%w: expected 1 key value, got %d", ErrInvalidKeyComponent, len(values))
+		}
+		key, ok := values[0].(string)
+		if !ok {
+			return "", ErrInvalidKeyType
+		}
+		return key, nil
+	}
+	if len(values) != len(c.config.PrimaryKeys) {
+		return "", fmt.Errorf("%w: expected %d key values, got %d", ErrInvalidKeyComponent, len(c.config.PrimaryKeys), len(values))
+	}
+
+	components, err := keyValueFields(values)
+	if err != nil {
+		return "", err
+	}
+	return encodeKey(components)
+}
+
+// keyValueFields перетворює значення Go (рядки, числа, DocumentField) на поля документа
+func keyValueFields(values []any) ([]DocumentField, error) {
+	fields := make([]DocumentField, len(values))
+	for i, v := range values {
+		if v == nil {
+			return nil, fmt.Errorf("%w: got null", ErrInvalidKeyComponent)
+		}
+		field, err := toDocumentField(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKeyComponent, err)
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+// encodeKey кодує компоненти ключа. Числа приводяться до канонічного вигляду,
+// тож 42, 42.0 і decimal "42.00" дають той самий ключ.
+func encodeKey(components []DocumentField) (string, error) {
+	canonical := make([]any, len(components))
+	for i, field := range components {
+		switch field.Type {
+		case DocumentFieldTypeString:
+			s, ok := field.Value.(string)
+			if !ok || s == "" {
+				return "", fmt.Errorf("%w: component %d is empty", ErrInvalidKeyComponent, i)
+			}
+			canonical[i] = s
+		case DocumentFieldTypeNumber, DocumentFieldTypeInteger, DocumentFieldTypeDecimal:
+			r, ok := numericRat(field.Value)
+			if !ok {
+				return "", fmt.Errorf("%w: component %d is not a finite number", ErrInvalidKeyComponent, i)
+			}
+			n, ok := canonicalNumber(r)
+			if !ok {
+				return "", fmt.Errorf("%w: component %d has no finite decimal form", ErrInvalidKeyComponent, i)
+			}
+			canonical[i] = n
+		default:
+			return "", fmt.Errorf("%w: component %d has type '%s'", ErrInvalidKeyComponent, i, field.Type)
+		}
+	}
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidKeyComponent, err)
+	}
+	return string(data), nil
+}
+
+// canonicalNumber записує число в десятковому вигляді без зайвих нулів
+func canonicalNumber(r *big.Rat) (json.Number, bool) {
+	if r.IsInt() {
+		return json.Number(r.Num().String()), true
+	}
+	// Скінченний десятковий запис існує, лише якщо знаменник має вигляд 2^a * 5^b
+	d := new(big.Int).Set(r.Denom())
+	two, five := big.NewInt(2), big.NewInt(5)
+	digits := 0
+	for _, p := range []*big.Int{two, five} {
+		n := 0
+		m := new(big.Int)
+		for {
+			q, rem := new(big.Int).QuoRem(d, p, m)
+			if rem.Sign() != 0 {
+				break
+			}
+			d = q
+			n++
+		}
+		digits = max(digits, n)
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return "", false
+	}
+	return json.Number(r.FloatString(digits)), true
+}
+
+// KeyRange задає діапазон первинних ключів для Scan.
+// From і To - префікси значень компонентів (включно); nil означає відсутність межі.
+// Наприклад, для ключа (account_id, date) From: []any{"acc-1"}, To: []any{"acc-1"}
+// повертає всі документи рахунку acc-1, впорядковані за датою.
+type KeyRange struct {
+	From []any
+	To   []any
+	Desc bool
+}
+
+// Scan повертає документи в порядку первинного ключа: спершу за першим компонентом,
+// далі за другим і т.д. Числові компоненти порівнюються як числа.
+func (c *Collection) Scan(r KeyRange) ([]Document, error) {
+	if c.config == nil {
+		return nil, errors.New("collection config is not initialized")
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	fields := c.config.keyFields()
+	if len(r.From) > len(fields) || len(r.To) > len(fields) {
+		return nil, fmt.Errorf("%w: range has more values than key fields", ErrInvalidKeyComponent)
+	}
+	from, err := keyValueFields(r.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := keyValueFields(r.To)
+	if err != nil {
+		return nil, err
+	}
+
+	type scanned struct {
+		key []DocumentField
+		doc Document
+	}
+	var entries []scanned
+	now := timeNow()
+	for _, doc := range c.documents {
+		if doc.expired(now) {
+			continue
+		}
+		key := make([]DocumentField, len(fields))
+		for i, name := range fields {
+			key[i] = doc.Fields[name]
+		}
+		if len(from) > 0 && compareIndexKeys(key[:len(from)], from) < 0 {
+			continue
+		}
+		if len(to) > 0 && compareIndexKeys(key[:len(to)], to) > 0 {
+			continue
+		}
+		entries = append(entries, scanned{key: key, doc: doc})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		cmp := compareIndexKeys(entries[i].key, entries[j].key)
+		if r.Desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	result := make([]Document, len(entries))
+	for i, e := range entries {
+		result[i] = e.doc
+	}
+	return result, nil
+}
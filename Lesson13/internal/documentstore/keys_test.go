@@ -0,0 +1,177 @@
+package documentstore
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func visitDoc(account string, day any, visits int64) Document {
+	dayField, _ := keyValueFields([]any{day})
+	return Document{Fields: map[string]DocumentField{
+		"account_id": {Type: DocumentFieldTypeString, Value: account},
+		"day":        dayField[0],
+		"visits":     {Type: DocumentFieldTypeInteger, Value: visits},
+	}}
+}
+
+func TestCollection_CompositeKey(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKeys: []string{"account_id", "day"}}}
+
+	key, _, err := c.Put(visitDoc("acc-1", "2024-01-02", 3))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if want := `["acc-1","2024-01-02"]`; key != want {
+		t.Errorf("Put() key = %s, want %s", key, want)
+	}
+
+	tests := []struct {
+		name    string
+		doc     Document
+		wantErr error
+	}{
+		{name: "Missing component", doc: Document{Fields: map[string]DocumentField{"account_id": {Type: DocumentFieldTypeString, Value: "acc-1"}}}},
+		{name: "Empty string component", doc: visitDoc("", "2024-01-02", 1), wantErr: ErrInvalidKeyComponent},
+		{name: "Bool component", doc: visitDoc("acc-1", true, 1), wantErr: ErrInvalidKeyComponent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := c.Put(tt.doc)
+			if err == nil {
+				t.Fatalf("Put() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Put() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	got, err := c.Get("acc-1", "2024-01-02")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Fields["visits"].Value != int64(3) {
+		t.Errorf("Get() got = %v", got)
+	}
+	if _, err := c.Get("acc-1"); !errors.Is(err, ErrInvalidKeyComponent) {
+		t.Errorf("Get() with one value error = %v, wantErr %v", err, ErrInvalidKeyComponent)
+	}
+	if err := c.Delete("acc-1", "2024-01-02"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if _, err := c.Get("acc-1", "2024-01-02"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Get() after Delete() error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+}
+
+func TestCollection_CompositeKeyNumbers(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKeys: []string{"account_id", "day"}}}
+	if _, _, err := c.Put(visitDoc("acc-1", 20240102, 1)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// Те саме число в іншому представленні замінює документ, а не додає новий
+	if _, _, err := c.Put(visitDoc("acc-1", json.Number("20240102.00"), 2)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if len(c.documents) != 1 {
+		t.Errorf("got %d documents, want 1", len(c.documents))
+	}
+	for _, day := range []any{20240102, int64(20240102), 20240102.0, json.Number("20240102")} {
+		got, err := c.Get("acc-1", day)
+		if err != nil {
+			t.Errorf("Get(%T) error = %v", day, err)
+			continue
+		}
+		if got.Fields["visits"].Value != int64(2) {
+			t.Errorf("Get(%T) got = %v", day, got)
+		}
+	}
+}
+
+func TestCollection_Scan(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKeys: []string{"account_id", "day"}}}
+	for _, d := range []Document{
+		visitDoc("acc-2", 1, 1),
+		visitDoc("acc-1", 10, 2),
+		visitDoc("acc-1", 9, 3),
+		visitDoc("acc-1", 2, 4),
+	} {
+		if _, _, err := c.Put(d); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		r    KeyRange
+		want []int64 // visits у порядку результату
+	}{
+		{name: "Full scan follows component order", r: KeyRange{}, want: []int64{4, 3, 2, 1}},
+		{name: "Prefix", r: KeyRange{From: []any{"acc-1"}, To: []any{"acc-1"}}, want: []int64{4, 3, 2}},
+		{name: "Prefix and range", r: KeyRange{From: []any{"acc-1", 3}, To: []any{"acc-1", 10}}, want: []int64{3, 2}},
+		{name: "Descending", r: KeyRange{From: []any{"acc-1"}, To: []any{"acc-1"}, Desc: true}, want: []int64{2, 3, 4}},
+		{name: "Open upper bound", r: KeyRange{From: []any{"acc-1", 10}}, want: []int64{2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := c.Scan(tt.r)
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			got := make([]int64, len(docs))
+			for i, d := range docs {
+				got[i] = d.Fields["visits"].Value.(int64)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_CreateCollectionInvalidPrimaryKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *CollectionConfig
+		wantErr error
+	}{
+		{name: "Both single and composite", cfg: &CollectionConfig{PrimaryKey: "id", PrimaryKeys: []string{"a", "b"}}, wantErr: ErrInvalidPrimaryKey},
+		{name: "Duplicate field", cfg: &CollectionConfig{PrimaryKeys: []string{"a", "a"}}, wantErr: ErrInvalidPrimaryKey},
+		{name: "Generated composite key", cfg: &CollectionConfig{PrimaryKeys: []string{"a", "b"}, KeyGeneration: KeyGenerationUUIDv4}, wantErr: ErrInvalidKeyGeneration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewStore().CreateCollection("c", tt.cfg); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateCollection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnsureCollectionFor_CompositeKey(t *testing.T) {
+	type dailyVisits struct {
+		AccountID string `json:"account_id" docstore:"pk"`
+		Day       string `json:"day" docstore:"pk"`
+		Visits    int    `json:"visits"`
+	}
+	visits, err := EnsureCollectionFor[dailyVisits](NewStore(), "visits")
+	if err != nil {
+		t.Fatalf("EnsureCollectionFor() error = %v", err)
+	}
+	if got := visits.Collection().config.PrimaryKeys; !reflect.DeepEqual(got, []string{"account_id", "day"}) {
+		t.Errorf("PrimaryKeys = %v, want [account_id day]", got)
+	}
+	v := dailyVisits{AccountID: "acc-1", Day: "2024-01-02", Visits: 5}
+	if _, err := visits.Put(v); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, err := visits.Get("acc-1", "2024-01-02")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got != v {
+		t.Errorf("Get() got = %+v, want %+v", got, v)
+	}
+}
@@ -0,0 +1,251 @@
+package documentstore
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrSchemaViolation = errors.New("document does not match collection schema")
+	ErrInvalidSchema   = errors.New("invalid collection schema")
+)
+
+// ValidationMode визначає, що робить Put з документом, який не відповідає схемі
+type ValidationMode string
+
+const (
+	ValidationModeStrict ValidationMode = "strict" // документ відхиляється
+	ValidationModeWarn   ValidationMode = "warn"   // порушення лише логуються
+	ValidationModeOff    ValidationMode = "off"    // схема ігнорується
+)
+
+// Schema описує очікувану форму документів колекції
+type Schema struct {
+	Fields map[string]FieldSchema `json:"fields"`
+
+	patterns map[string]*regexp.Regexp // скомпільовані Pattern; заповнює Check
+}
+
+// FieldSchema - обмеження для одного поля. Нульові значення означають "без обмеження".
+type FieldSchema struct {
+	Type      DocumentFieldType      `json:"type,omitempty"`
+	Required  bool                   `json:"required,omitempty"`
+	Enum      []any                  `json:"enum,omitempty"`
+	Min       *float64               `json:"min,omitempty"`
+	Max       *float64               `json:"max,omitempty"`
+	MinLength *int                   `json:"minLength,omitempty"`
+	MaxLength *int                   `json:"maxLength,omitempty"`
+	Pattern   string                 `json:"pattern,omitempty"`
+	Fields    map[string]FieldSchema `json:"fields,omitempty"` // схема вкладеного об'єкта
+}
+
+// SchemaViolation - одне порушення схеми; Path - шлях до поля через крапку
+type SchemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError містить усі порушення схеми, знайдені в документі
+type ValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, fmt.Sprintf("%s: %s", v.Path, v.Message))
+	}
+	return fmt.Sprintf("%s: %s", ErrSchemaViolation, strings.Join(parts, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrSchemaViolation
+}
+
+// checkSchema перевіряє схему та режим валідації конфігурації
+func (cfg *CollectionConfig) checkSchema() error {
+	if cfg == nil {
+		return nil
+	}
+	switch cfg.ValidationMode {
+	case "", ValidationModeStrict, ValidationModeWarn, ValidationModeOff:
+	default:
+		return fmt.Errorf("%w: unknown validation mode '%s'", ErrInvalidSchema, cfg.ValidationMode)
+	}
+	return cfg.Schema.Check()
+}
+
+// Check перевіряє саму схему та компілює її регулярні вирази, щоб Validate
+// не компілював їх для кожного документа
+func (s *Schema) Check() error {
+	if s == nil {
+		return nil
+	}
+	patterns := make(map[string]*regexp.Regexp)
+	if err := checkFieldSchemas("", s.Fields, patterns); err != nil {
+		return err
+	}
+	s.patterns = patterns
+	return nil
+}
+
+func checkFieldSchemas(prefix string, fields map[string]FieldSchema, patterns map[string]*regexp.Regexp) error {
+	for name, fs := range fields {
+		path := joinPath(prefix, name)
+		if fs.Pattern != "" {
+			re, err := regexp.Compile(fs.Pattern)
+			if err != nil {
+				return fmt.Errorf("%w: field '%s': %v", ErrInvalidSchema, path, err)
+			}
+			patterns[fs.Pattern] = re
+		}
+		if fs.Min != nil && fs.Max != nil && *fs.Min > *fs.Max {
+			return fmt.Errorf("%w: field '%s': min is greater than max", ErrInvalidSchema, path)
+		}
+		if err := checkFieldSchemas(path, fs.Fields, patterns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate повертає *ValidationError зі списком усіх порушень або nil
+func (s *Schema) Validate(doc Document) error {
+	if s == nil {
+		return nil
+	}
+	var violations []SchemaViolation
+	validateFields("", s.Fields, doc.Fields, s.patterns, &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+	return &ValidationError{Violations: violations}
+}
+
+func validateFields(prefix string, schema map[string]FieldSchema, fields map[string]DocumentField, patterns map[string]*regexp.Regexp, violations *[]SchemaViolation) {
+	for name, fs := range schema {
+		path := joinPath(prefix, name)
+		field, ok := fields[name]
+		if !ok {
+			if fs.Required {
+				*violations = append(*violations, SchemaViolation{Path: path, Message: "required field is missing"})
+			}
+			continue
+		}
+		validateField(path, fs, field, patterns, violations)
+	}
+}
+
+func validateField(path string, fs FieldSchema, field DocumentField, patterns map[string]*regexp.Regexp, violations *[]SchemaViolation) {
+	add := func(format string, args ...any) {
+		*violations = append(*violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if field.Type == DocumentFieldTypeNull {
+		// null допустимий лише для необов'язкових полів
+		if fs.Required {
+			add("required field is null")
+		}
+		return
+	}
+
+	if fs.Type != "" && !typeMatches(fs.Type, field.Type) {
+		add("expected type '%s', got '%s'", fs.Type, field.Type)
+		return
+	}
+
+	if len(fs.Enum) > 0 {
+		found := false
+		for _, allowed := range fs.Enum {
+			if valuesEqual(field.Interface(), allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("value %v is not one of %v", field.Value, fs.Enum)
+		}
+	}
+
+	// Межі порівнюються точно, щоб decimal 19.99 не виходив за Max 19.99
+	if num, ok := decimalRat(field.Value); ok {
+		if fs.Min != nil {
+			if lo, ok := decimalRat(*fs.Min); ok && num.Cmp(lo) < 0 {
+				add("value %v is less than minimum %v", field.Value, *fs.Min)
+			}
+		}
+		if fs.Max != nil {
+			if hi, ok := decimalRat(*fs.Max); ok && num.Cmp(hi) > 0 {
+				add("value %v is greater than maximum %v", field.Value, *fs.Max)
+			}
+		}
+	}
+
+	if str, ok := field.Value.(string); ok {
+		length := utf8.RuneCountInString(str)
+		if fs.MinLength != nil && length < *fs.MinLength {
+			add("length %d is less than minimum length %d", length, *fs.MinLength)
+		}
+		if fs.MaxLength != nil && length > *fs.MaxLength {
+			add("length %d is greater than maximum length %d", length, *fs.MaxLength)
+		}
+		if fs.Pattern != "" {
+			if re, err := compiledPattern(patterns, fs.Pattern); err != nil {
+				add("invalid pattern '%s': %v", fs.Pattern, err)
+			} else if !re.MatchString(str) {
+				add("value '%s' does not match pattern '%s'", str, fs.Pattern)
+			}
+		}
+	}
+
+	if len(fs.Fields) > 0 {
+		nested, ok := field.Value.(map[string]DocumentField)
+		if !ok {
+			add("expected nested object")
+			return
+		}
+		validateFields(path, fs.Fields, nested, patterns, violations)
+	}
+}
+
+// compiledPattern бере вираз, скомпільований у Check; схему, яку не перевіряли
+// через Check, доводиться компілювати на місці
+func compiledPattern(patterns map[string]*regexp.Regexp, pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns[pattern]; ok {
+		return re, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// typeMatches дозволяє полю типу number приймати будь-яке число,
+// тоді як integer і decimal вимагають точного збігу типу
+func typeMatches(want, got DocumentFieldType) bool {
+	if want == got {
+		return true
+	}
+	return want == DocumentFieldTypeNumber && (got == DocumentFieldTypeInteger || got == DocumentFieldTypeDecimal)
+}
+
+func valuesEqual(a, b any) bool {
+	ra, okA := decimalRat(a)
+	rb, okB := decimalRat(b)
+	if okA && okB {
+		return ra.Cmp(rb) == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
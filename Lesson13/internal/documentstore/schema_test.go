@@ -0,0 +1,182 @@
+package documentstore
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := &Schema{Fields: map[string]FieldSchema{
+		"id":     {Type: DocumentFieldTypeString, Required: true},
+		"email":  {Type: DocumentFieldTypeString, Required: true, Pattern: `^[^@]+@[^@]+$`},
+		"name":   {Type: DocumentFieldTypeString, MinLength: ptr(2), MaxLength: ptr(10)},
+		"age":    {Type: DocumentFieldTypeNumber, Min: ptr(0.0), Max: ptr(150.0)},
+		"price":  {Type: DocumentFieldTypeNumber, Min: ptr(0.1), Max: ptr(19.99)},
+		"status": {Enum: []any{"active", "blocked"}},
+		"address": {Type: DocumentFieldTypeObject, Fields: map[string]FieldSchema{
+			"city": {Type: DocumentFieldTypeString, Required: true},
+			"zip":  {Type: DocumentFieldTypeNumber},
+		}},
+	}}
+
+	tests := []struct {
+		name string
+		doc  Document
+		want []SchemaViolation
+	}{
+		{
+			name: "Valid document",
+			doc: Document{Fields: map[string]DocumentField{
+				"id":      {Type: DocumentFieldTypeString, Value: "1"},
+				"email":   {Type: DocumentFieldTypeString, Value: "a@b.c"},
+				"name":    {Type: DocumentFieldTypeString, Value: "Alice"},
+				"age":     {Type: DocumentFieldTypeNumber, Value: 30.0},
+				"price":   {Type: DocumentFieldTypeDecimal, Value: json.Number("0.1")},
+				"status":  {Type: DocumentFieldTypeString, Value: "active"},
+				"address": {Type: DocumentFieldTypeObject, Value: map[string]DocumentField{"city": {Type: DocumentFieldTypeString, Value: "Kyiv"}, "zip": {Type: DocumentFieldTypeNumber, Value: 1001.0}}},
+			}},
+			want: nil,
+		},
+		{
+			name: "Decimal at maximum",
+			doc: Document{Fields: map[string]DocumentField{
+				"id":    {Type: DocumentFieldTypeString, Value: "1"},
+				"email": {Type: DocumentFieldTypeString, Value: "a@b.c"},
+				"price": {Type: DocumentFieldTypeDecimal, Value: json.Number("19.99")},
+			}},
+			want: nil,
+		},
+		{
+			name: "Decimal just below minimum",
+			doc: Document{Fields: map[string]DocumentField{
+				"id":    {Type: DocumentFieldTypeString, Value: "1"},
+				"email": {Type: DocumentFieldTypeString, Value: "a@b.c"},
+				"price": {Type: DocumentFieldTypeDecimal, Value: json.Number("0.09999999999999999999")},
+			}},
+			want: []SchemaViolation{{Path: "price", Message: "value 0.09999999999999999999 is less than minimum 0.1"}},
+		},
+		{
+			name: "Every violation is reported",
+			doc: Document{Fields: map[string]DocumentField{
+				"email":   {Type: DocumentFieldTypeString, Value: "not-an-email"},
+				"name":    {Type: DocumentFieldTypeString, Value: "A"},
+				"age":     {Type: DocumentFieldTypeNumber, Value: -1.0},
+				"status":  {Type: DocumentFieldTypeString, Value: "deleted"},
+				"address": {Type: DocumentFieldTypeObject, Value: map[string]DocumentField{"zip": {Type: DocumentFieldTypeString, Value: "01001"}}},
+			}},
+			want: []SchemaViolation{
+				{Path: "address.city", Message: "required field is missing"},
+				{Path: "address.zip", Message: "expected type 'number', got 'string'"},
+				{Path: "age", Message: "value -1 is less than minimum 0"},
+				{Path: "email", Message: "value 'not-an-email' does not match pattern '^[^@]+@[^@]+$'"},
+				{Path: "id", Message: "required field is missing"},
+				{Path: "name", Message: "length 1 is less than minimum length 2"},
+				{Path: "status", Message: "value deleted is not one of [active blocked]"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(tt.doc)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+			if !reflect.DeepEqual(vErr.Violations, tt.want) {
+				t.Errorf("Validate() got = %v, want %v", vErr.Violations, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollection_PutValidationMode(t *testing.T) {
+	schema := &Schema{Fields: map[string]FieldSchema{
+		"age": {Type: DocumentFieldTypeNumber, Required: true},
+	}}
+	doc := Document{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: "1"}}}
+
+	tests := []struct {
+		name    string
+		mode    ValidationMode
+		wantErr bool
+	}{
+		{name: "Default is strict", mode: "", wantErr: true},
+		{name: "Strict", mode: ValidationModeStrict, wantErr: true},
+		{name: "Warn only", mode: ValidationModeWarn, wantErr: false},
+		{name: "Off", mode: ValidationModeOff, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collection{config: &CollectionConfig{PrimaryKey: "id", Schema: schema, ValidationMode: tt.mode}}
+			_, _, err := c.Put(doc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Put() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrSchemaViolation) {
+				t.Errorf("Put() error = %v, want ErrSchemaViolation", err)
+			}
+		})
+	}
+}
+
+func TestStore_CreateCollectionInvalidSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *CollectionConfig
+	}{
+		{
+			name: "Invalid pattern",
+			cfg: &CollectionConfig{PrimaryKey: "id", Schema: &Schema{Fields: map[string]FieldSchema{
+				"name": {Pattern: "("},
+			}}},
+		},
+		{
+			name: "Unknown validation mode",
+			cfg: &CollectionConfig{PrimaryKey: "id", ValidationMode: "lenient", Schema: &Schema{Fields: map[string]FieldSchema{
+				"name": {Type: DocumentFieldTypeString},
+			}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewStore().CreateCollection("users", tt.cfg); !errors.Is(err, ErrInvalidSchema) {
+				t.Errorf("CreateCollection() error = %v, wantErr %v", err, ErrInvalidSchema)
+			}
+		})
+	}
+}
+
+func TestSchema_CheckCompilesPatterns(t *testing.T) {
+	schema := &Schema{Fields: map[string]FieldSchema{
+		"name":    {Pattern: "^[a-z]+$"},
+		"address": {Fields: map[string]FieldSchema{"zip": {Pattern: "^[0-9]{5}$"}}},
+	}}
+	if err := schema.Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(schema.patterns) != 2 {
+		t.Errorf("compiled patterns = %d, want 2", len(schema.patterns))
+	}
+	doc := Document{Fields: map[string]DocumentField{
+		"name": {Type: DocumentFieldTypeString, Value: "Bob"},
+		"address": {Type: DocumentFieldTypeObject, Value: map[string]DocumentField{
+			"zip": {Type: DocumentFieldTypeString, Value: "12345"},
+		}},
+	}}
+	var vErr *ValidationError
+	if err := schema.Validate(doc); !errors.As(err, &vErr) || len(vErr.Violations) != 1 || vErr.Violations[0].Path != "name" {
+		t.Errorf("Validate() error = %v, want one violation for name", err)
+	}
+}
@@ -1,10 +1,18 @@
 package documentstore
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Визначення помилок
@@ -20,6 +28,7 @@ var (
 )
 
 type Store struct {
+	mu          sync.RWMutex // захищає collections
 	collections map[string]*Collection
 }
 
@@ -32,22 +41,30 @@ func NewStore() *Store {
 
 // CreateCollection створює нову колекцію, якщо вона не існує
 func (s *Store) CreateCollection(name string, cfg *CollectionConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if _, exists := s.collections[name]; exists {
 		slog.Warn("COLLECTION CREATE FAILED", slog.String("name", name), slog.String("message", fmt.Sprintf("Колекція '%s' вже існує", name)))
 		return ErrCollectionAlreadyExists
 	}
+	if message, err := cfg.check(); err != nil {
+		slog.Warn("COLLECTION CREATE FAILED", slog.String("name", name), slog.Any("error", err), slog.String("message", fmt.Sprintf("%s '%s'", message, name)))
+		return err
+	}
 
 	collection := &Collection{
 		config:    cfg,
 		documents: make(map[string]Document),
 	}
 	s.collections[name] = collection
-	slog.Info("COLLECTION CREATED", slog.String("name", name), slog.String("primaryKey", cfg.PrimaryKey), slog.String("message", fmt.Sprintf("Колекція '%s' створена з первинним ключем '%s'", name, cfg.PrimaryKey)))
+	slog.Info("COLLECTION CREATED", slog.String("name", name), slog.Any("primaryKey", cfg.keyFields()), slog.String("message", fmt.Sprintf("Колекція '%s' створена з первинним ключем %v", name, cfg.keyFields())))
 	return nil
 }
 
 // GetCollection повертає колекцію за її назвою
 func (s *Store) GetCollection(name string) (*Collection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	collection, exists := s.collections[name]
 	if !exists {
 		slog.Warn("COLLECTION GET FAILED", slog.String("name", name), slog.String("message", fmt.Sprintf("Колекція '%s' не знайдена", name)))
@@ -59,6 +76,8 @@ func (s *Store) GetCollection(name string) (*Collection, error) {
 
 // DeleteCollection видаляє колекцію за її назвою
 func (s *Store) DeleteCollection(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if _, exists := s.collections[name]; !exists {
 		slog.Warn("COLLECTION DELETE FAILED", slog.String("name", name), slog.String("message", fmt.Sprintf("Колекція '%s' не знайдена", name)))
 		return ErrCollectionNotFound
@@ -70,6 +89,8 @@ func (s *Store) DeleteCollection(name string) error {
 
 // GetAllCollections повертає мапу всіх колекцій у сховищі.
 func (s *Store) GetAllCollections() map[string]*Collection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	allCollections := make(map[string]*Collection, len(s.collections))
 	for name, col := range s.collections {
 		allCollections[name] = col
@@ -86,9 +107,11 @@ func MarshalDocument(input any) (*Document, error) {
 	}
 	slog.Debug("MARSHAL DOCUMENT", slog.Any("input", input), slog.String("json", string(data)), slog.String("message", fmt.Sprintf("Об'єкт '%v' успішно маршалізовано в JSON: '%s'", input, string(data))))
 
-	// Розпарсимо JSON у map[string]any
+	// Розпарсимо JSON у map[string]any; числа читаємо як json.Number, щоб не втратити точність
 	var raw map[string]any
-	err = json.Unmarshal(data, &raw)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&raw)
 	if err != nil {
 		slog.Error("MARSHAL DOCUMENT FAILED", slog.String("json", string(data)), slog.Any("error", err), slog.String("message", fmt.Sprintf("Помилка демаршалінгу JSON '%s' до map: %v", string(data), err)))
 		return nil, fmt.Errorf("%w: %v", ErrUnmarshalToMapFailed, err)
@@ -100,6 +123,14 @@ func MarshalDocument(input any) (*Document, error) {
 		Fields: make(map[string]DocumentField),
 	}
 
+	// Теги docstore та поля time.Time/[]byte (у JSON вони стають рядками) беремо зі структури,
+	// зокрема з вкладених структур, масивів і map
+	meta, err := structMetaOf(reflect.TypeOf(input))
+	if err != nil {
+		slog.Error("MARSHAL DOCUMENT FAILED", slog.Any("error", err), slog.String("message", "Невалідні теги docstore"))
+		return nil, fmt.Errorf("%w: %v", ErrMarshalFailed, err)
+	}
+
 	// Перетворюємо кожне поле у відповідний тип DocumentField
 	for key, value := range raw {
 		field, err := toDocumentField(value)
@@ -107,40 +138,181 @@ func MarshalDocument(input any) (*Document, error) {
 			slog.Error("MARSHAL DOCUMENT FAILED", slog.String("key", key), slog.Any("value", value), slog.Any("error", err), slog.String("message", fmt.Sprintf("Непідтримуваний тип поля '%s': %v", key, value)))
 			return nil, fmt.Errorf("%w: field '%s'", ErrUnsupportedDocumentField, key)
 		}
+		if hint, ok := meta.hints[key]; ok {
+			field, err = hint.apply(field)
+			if err != nil {
+				slog.Error("MARSHAL DOCUMENT FAILED", slog.String("key", key), slog.Any("error", err), slog.String("message", fmt.Sprintf("Не вдалося відновити тип поля '%s'", key)))
+				return nil, fmt.Errorf("%w: field '%s': %v", ErrMarshalFailed, key, err)
+			}
+		}
 		doc.Fields[key] = field
 		slog.Debug("MARSHAL DOCUMENT", slog.String("key", key), slog.String("type", fmt.Sprintf("%T", value)), slog.String("message", fmt.Sprintf("Поле '%s' з типом '%T' успішно перетворено на DocumentField", key, value)))
 	}
+	doc.Fields = meta.toDocumentNames(doc.Fields)
 	slog.Debug("MARSHAL DOCUMENT", slog.Any("input", input), slog.String("message", fmt.Sprintf("Документ успішно створено з '%v'", input)))
 	return doc, nil
 }
 
 func toDocumentField(value any) (DocumentField, error) {
 	switch v := value.(type) {
+	case nil: // JSON null, наприклад nil-вказівник у структурі
+		slog.Debug("TO DOCUMENT FIELD", slog.String("type", "null"))
+		return DocumentField{Type: DocumentFieldTypeNull, Value: nil}, nil
 	case string:
 		slog.Debug("TO DOCUMENT FIELD", slog.String("type", "string"), slog.Any("value", v))
 		return DocumentField{Type: DocumentFieldTypeString, Value: v}, nil
-	case float64: // JSON числа парсяться як float64
+	case json.Number:
+		slog.Debug("TO DOCUMENT FIELD", slog.String("type", "json.Number"), slog.Any("value", v))
+		return numberField(v), nil
+	case float64: // JSON числа, розпарсені без UseNumber
 		slog.Debug("TO DOCUMENT FIELD", slog.String("type", "float64"), slog.Any("value", v))
 		return DocumentField{Type: DocumentFieldTypeNumber, Value: v}, nil
+	case time.Time:
+		return DocumentField{Type: DocumentFieldTypeTime, Value: v}, nil
+	case []byte:
+		return DocumentField{Type: DocumentFieldTypeBinary, Value: v}, nil
 	case bool:
 		slog.Debug("TO DOCUMENT FIELD", slog.String("type", "bool"), slog.Any("value", v))
 		return DocumentField{Type: DocumentFieldTypeBool, Value: v}, nil
 	case []any:
 		slog.Debug("TO DOCUMENT FIELD", slog.String("type", "[]any"), slog.Any("value", v))
-		return DocumentField{Type: DocumentFieldTypeArray, Value: v}, nil
+		items := make([]DocumentField, len(v))
+		for i, item := range v {
+			field, err := toDocumentField(item)
+			if err != nil {
+				return DocumentField{}, err
+			}
+			items[i] = field
+		}
+		return DocumentField{Type: DocumentFieldTypeArray, Value: items}, nil
 	case map[string]any:
 		slog.Debug("TO DOCUMENT FIELD", slog.String("type", "map[string]any"), slog.Any("value", v))
+		fields := make(map[string]DocumentField, len(v))
+		for key, item := range v {
+			field, err := toDocumentField(item)
+			if err != nil {
+				return DocumentField{}, err
+			}
+			fields[key] = field
+		}
+		return DocumentField{Type: DocumentFieldTypeObject, Value: fields}, nil
+	case DocumentField:
+		return v, nil
+	case []DocumentField:
+		return DocumentField{Type: DocumentFieldTypeArray, Value: v}, nil
+	case map[string]DocumentField:
 		return DocumentField{Type: DocumentFieldTypeObject, Value: v}, nil
+	}
+	// Цілі та дробові числа Go будь-якого розміру (int, uint32, float32, ...)
+	switch rv := reflect.ValueOf(value); {
+	case rv.CanInt():
+		return DocumentField{Type: DocumentFieldTypeInteger, Value: rv.Int()}, nil
+	case rv.CanUint():
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return DocumentField{Type: DocumentFieldTypeInteger, Value: int64(u)}, nil
+		}
+		return DocumentField{Type: DocumentFieldTypeDecimal, Value: json.Number(strconv.FormatUint(rv.Uint(), 10))}, nil
+	case rv.CanFloat():
+		return DocumentField{Type: DocumentFieldTypeNumber, Value: rv.Float()}, nil
 	default:
 		slog.Error("TO DOCUMENT FIELD FAILED", slog.String("type", fmt.Sprintf("%T", value)), slog.Any("value", value), slog.String("message", fmt.Sprintf("Непідтримуваний тип значення '%T': %v", value, value)))
 		return DocumentField{}, ErrUnsupportedDocumentField
 	}
 }
 
-// UnmarshalDocument перетворює документ назад в тип структури
-func UnmarshalDocument(doc *Document, output any) error {
+// hintedField відновлює time.Time або []byte з рядка, на який їх перетворив encoding/json
+func hintedField(hint DocumentFieldType, value string) (DocumentField, error) {
+	switch hint {
+	case DocumentFieldTypeTime:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return DocumentField{}, err
+		}
+		return DocumentField{Type: DocumentFieldTypeTime, Value: t}, nil
+	case DocumentFieldTypeBinary:
+		b, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return DocumentField{}, err
+		}
+		return DocumentField{Type: DocumentFieldTypeBinary, Value: b}, nil
+	default:
+		return DocumentField{Type: DocumentFieldTypeString, Value: value}, nil
+	}
+}
+
+// numberField обирає тип для JSON-числа: ціле в межах int64 -> integer,
+// решта (великі цілі, дроби, експоненційний запис) -> decimal з точним записом,
+// щоб, наприклад, грошові суми 19.99 не ставали float64.
+func numberField(n json.Number) DocumentField {
+	literal := n.String()
+	if !strings.ContainsAny(literal, ".eE") {
+		if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+			return DocumentField{Type: DocumentFieldTypeInteger, Value: i}
+		}
+	}
+	return DocumentField{Type: DocumentFieldTypeDecimal, Value: n}
+}
+
+// UnmarshalOption налаштовує UnmarshalDocument
+type UnmarshalOption func(*unmarshalOptions)
+
+type unmarshalOptions struct {
+	legacyData bool
+}
+
+// WithLegacyDataField читає документи старого формату, де весь об'єкт
+// зберігався як JSON-рядок у полі "data"
+func WithLegacyDataField() UnmarshalOption {
+	return func(o *unmarshalOptions) {
+		o.legacyData = true
+	}
+}
+
+// UnmarshalDocument перетворює документ назад в тип структури.
+// Поля документа зіставляються з полями структури за json-тегами,
+// тож документ, створений MarshalDocument, читається без втрат.
+func UnmarshalDocument(doc *Document, output any, opts ...UnmarshalOption) error {
+	var o unmarshalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if doc == nil {
+		return fmt.Errorf("%w: document is nil", ErrUnmarshalFailed)
+	}
+	if o.legacyData {
+		return unmarshalLegacyDocument(doc, output)
+	}
+
+	meta, err := structMetaOf(reflect.TypeOf(output))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnmarshalFailed, err)
+	}
+	data, err := json.Marshal(meta.toJSONNames(doc.ToMap()))
+	if err != nil {
+		slog.Error("UNMARSHAL DOCUMENT FAILED", slog.Any("document", doc), slog.Any("error", err), slog.String("message", fmt.Sprintf("Помилка маршалінгу полів документа '%v'", doc)))
+		return fmt.Errorf("%w: %v", ErrUnmarshalFailed, err)
+	}
+
+	// UseNumber зберігає точність чисел, якщо output - map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(output); err != nil {
+		slog.Error("UNMARSHAL DOCUMENT FAILED", slog.String("data", string(data)), slog.String("outputType", fmt.Sprintf("%T", output)), slog.Any("error", err), slog.String("message", fmt.Sprintf("Помилка демаршалінгу JSON '%s' в об'єкт '%T': %v", string(data), output, err)))
+		return fmt.Errorf("%w: %v", ErrUnmarshalFailed, err)
+	}
+	slog.Debug("UNMARSHAL DOCUMENT", slog.Any("document", doc), slog.String("outputType", fmt.Sprintf("%T", output)), slog.String("message", fmt.Sprintf("Документ '%v' успішно демаршалізовано в об'єкт '%T'", doc, output)))
+	return nil
+}
+
+// unmarshalLegacyDocument читає об'єкт із JSON-рядка в полі "data"
+func unmarshalLegacyDocument(doc *Document, output any) error {
 	// Перевіряємо, чи існує поле "data" в документі
 	dataField, ok := doc.Fields["data"]
+	if ok && dataField.Type == DocumentFieldTypeNull {
+		// Як і json.Unmarshal для "null", залишаємо об'єкт без змін
+		slog.Debug("UNMARSHAL DOCUMENT", slog.Any("document", doc), slog.String("message", "Поле 'data' містить null, об'єкт не змінено"))
+		return nil
+	}
 	if !ok || dataField.Type != DocumentFieldTypeString {
 		slog.Warn("UNMARSHAL DOCUMENT FAILED", slog.Any("document", doc), slog.String("message", fmt.Sprintf("Відсутнє або неваліднe поле 'data' у документі '%v'", doc)))
 		return fmt.Errorf("%w: missing or invalid 'data' field", ErrInvalidDataField)
@@ -166,6 +338,8 @@ func UnmarshalDocument(doc *Document, output any) error {
 
 // NumCollections повертає кількість колекцій у сховищі.
 func (s *Store) NumCollections() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	slog.Debug("NumCollections", slog.Int("count", len(s.collections)))
 	return len(s.collections)
 }
@@ -1,8 +1,10 @@
 package documentstore
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestCollection_GetAll(t *testing.T) {
@@ -86,7 +88,7 @@ func TestMarshalDocument(t *testing.T) {
 			want: &Document{Fields: map[string]DocumentField{
 				"id":   {Type: DocumentFieldTypeString, Value: "1"},
 				"name": {Type: DocumentFieldTypeString, Value: "Alice"},
-				"age":  {Type: DocumentFieldTypeNumber, Value: 30.0},
+				"age":  {Type: DocumentFieldTypeInteger, Value: int64(30)},
 			}},
 			wantErr: false,
 		},
@@ -110,13 +112,55 @@ func TestMarshalDocument(t *testing.T) {
 			}{City: "Kharkiv", Country: "Ukraine"}}},
 			want: &Document{Fields: map[string]DocumentField{
 				"id": {Type: DocumentFieldTypeString, Value: "1"},
-				"info": {Type: DocumentFieldTypeObject, Value: map[string]any{
-					"city":    "Kharkiv",
-					"country": "Ukraine",
+				"info": {Type: DocumentFieldTypeObject, Value: map[string]DocumentField{
+					"city":    {Type: DocumentFieldTypeString, Value: "Kharkiv"},
+					"country": {Type: DocumentFieldTypeString, Value: "Ukraine"},
 				}},
 			}},
 			wantErr: false,
 		},
+		{
+			name: "Integer, float and decimal numbers",
+			args: args{input: struct {
+				ID     int64       `json:"id"`
+				Ratio  float64     `json:"ratio"`
+				Amount json.Number `json:"amount"`
+			}{ID: 9007199254740993, Ratio: 0.25, Amount: "12345678901234567.89"}},
+			want: &Document{Fields: map[string]DocumentField{
+				"id":     {Type: DocumentFieldTypeInteger, Value: int64(9007199254740993)},
+				"ratio":  {Type: DocumentFieldTypeDecimal, Value: json.Number("0.25")},
+				"amount": {Type: DocumentFieldTypeDecimal, Value: json.Number("12345678901234567.89")},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "Time and binary fields",
+			args: args{input: struct {
+				ID        string     `json:"id"`
+				CreatedAt time.Time  `json:"created_at"`
+				DeletedAt *time.Time `json:"deleted_at"`
+				Avatar    []byte     `json:"avatar"`
+			}{ID: "1", CreatedAt: time.Date(2024, 5, 1, 10, 30, 0, 123456789, time.UTC), Avatar: []byte{0xde, 0xad, 0xbe, 0xef}}},
+			want: &Document{Fields: map[string]DocumentField{
+				"id":         {Type: DocumentFieldTypeString, Value: "1"},
+				"created_at": {Type: DocumentFieldTypeTime, Value: time.Date(2024, 5, 1, 10, 30, 0, 123456789, time.UTC)},
+				"deleted_at": {Type: DocumentFieldTypeNull, Value: nil},
+				"avatar":     {Type: DocumentFieldTypeBinary, Value: []byte{0xde, 0xad, 0xbe, 0xef}},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "Null field",
+			args: args{input: struct {
+				ID       string  `json:"id"`
+				Nickname *string `json:"nickname"`
+			}{ID: "1"}},
+			want: &Document{Fields: map[string]DocumentField{
+				"id":       {Type: DocumentFieldTypeString, Value: "1"},
+				"nickname": {Type: DocumentFieldTypeNull, Value: nil},
+			}},
+			wantErr: false,
+		},
 		{
 			name: "Array of strings",
 			args: args{input: struct {
@@ -124,8 +168,11 @@ func TestMarshalDocument(t *testing.T) {
 				Hobbies []string `json:"hobbies"`
 			}{ID: "1", Hobbies: []string{"reading", "hiking"}}},
 			want: &Document{Fields: map[string]DocumentField{
-				"id":      {Type: DocumentFieldTypeString, Value: "1"},
-				"hobbies": {Type: DocumentFieldTypeArray, Value: []any{"reading", "hiking"}},
+				"id": {Type: DocumentFieldTypeString, Value: "1"},
+				"hobbies": {Type: DocumentFieldTypeArray, Value: []DocumentField{
+					{Type: DocumentFieldTypeString, Value: "reading"},
+					{Type: DocumentFieldTypeString, Value: "hiking"},
+				}},
 			}},
 			wantErr: false,
 		},
@@ -366,7 +413,7 @@ func TestStore_NumCollections(t *testing.T) {
 	}
 }
 
-func TestUnmarshalDocument(t *testing.T) {
+func TestUnmarshalDocument_LegacyData(t *testing.T) {
 	type args struct {
 		doc    *Document
 		output any
@@ -406,6 +453,21 @@ func TestUnmarshalDocument(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Null data field",
+			args: args{
+				doc: &Document{Fields: map[string]DocumentField{
+					"data": {Type: DocumentFieldTypeNull, Value: nil},
+				}},
+				output: &struct {
+					ID string `json:"id"`
+				}{},
+			},
+			wantErr: false,
+			wantOutput: &struct {
+				ID string `json:"id"`
+			}{},
+		},
 		{
 			name: "Invalid data field type",
 			args: args{
@@ -424,7 +486,7 @@ func TestUnmarshalDocument(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := UnmarshalDocument(tt.args.doc, tt.args.output)
+			err := UnmarshalDocument(tt.args.doc, tt.args.output, WithLegacyDataField())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UnmarshalDocument() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -434,3 +496,119 @@ func TestUnmarshalDocument(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalDocument_RoundTrip(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip,omitempty"`
+	}
+	type Account struct {
+		ID        int64      `json:"id"`
+		Name      string     `json:"full_name"`
+		Balance   float64    `json:"balance"`
+		Tags      []string   `json:"tags"`
+		Address   Address    `json:"address"`
+		Manager   *string    `json:"manager"`
+		CreatedAt time.Time  `json:"created_at"`
+		ClosedAt  *time.Time `json:"closed_at"`
+		Avatar    []byte     `json:"avatar"`
+		Internal  string     `json:"-"`
+	}
+	in := Account{
+		ID:        9007199254740993,
+		Name:      "Alice",
+		Balance:   12.5,
+		Tags:      []string{"vip"},
+		Address:   Address{City: "Kyiv"},
+		CreatedAt: time.Date(2024, 5, 1, 10, 30, 0, 1, time.UTC),
+		Avatar:    []byte{1, 2, 3},
+		Internal:  "not stored",
+	}
+
+	doc, err := MarshalDocument(in)
+	if err != nil {
+		t.Fatalf("MarshalDocument() error = %v", err)
+	}
+	var out Account
+	if err := UnmarshalDocument(doc, &out); err != nil {
+		t.Fatalf("UnmarshalDocument() error = %v", err)
+	}
+	in.Internal = ""
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("UnmarshalDocument() got = %+v, want %+v", out, in)
+	}
+
+	var generic map[string]any
+	if err := UnmarshalDocument(doc, &generic); err != nil {
+		t.Fatalf("UnmarshalDocument() error = %v", err)
+	}
+	if got := generic["id"]; got != json.Number("9007199254740993") {
+		t.Errorf("UnmarshalDocument() id = %v, want exact 9007199254740993", got)
+	}
+}
+
+func TestMarshalDocument_NestedTypeHints(t *testing.T) {
+	type Event struct {
+		At   time.Time `json:"at"`
+		Blob []byte    `json:"blob"`
+	}
+	type Audit struct {
+		Event
+		Last   *Event               `json:"last"`
+		Events []Event              `json:"events"`
+		ByName map[string]time.Time `json:"by_name"`
+	}
+	type Node struct {
+		Seen     time.Time `json:"seen"`
+		Children []Node    `json:"children"`
+	}
+	type Record struct {
+		ID    string `json:"id"`
+		Audit Audit  `json:"audit"`
+		Tree  Node   `json:"tree"`
+	}
+	at := time.Date(2024, 5, 1, 10, 30, 0, 1, time.UTC)
+	in := Record{
+		ID: "1",
+		Audit: Audit{
+			Event:  Event{At: at, Blob: []byte{1}},
+			Last:   &Event{At: at.Add(time.Hour), Blob: []byte{2}},
+			Events: []Event{{At: at, Blob: []byte{3}}},
+			ByName: map[string]time.Time{"a": at},
+		},
+		Tree: Node{Seen: at, Children: []Node{{Seen: at}}},
+	}
+
+	doc, err := MarshalDocument(in)
+	if err != nil {
+		t.Fatalf("MarshalDocument() error = %v", err)
+	}
+	audit := doc.Fields["audit"].Value.(map[string]DocumentField)
+	tests := []struct {
+		name  string
+		field DocumentField
+		want  DocumentFieldType
+	}{
+		{name: "Embedded time", field: audit["at"], want: DocumentFieldTypeTime},
+		{name: "Embedded bytes", field: audit["blob"], want: DocumentFieldTypeBinary},
+		{name: "Pointer struct", field: audit["last"].Value.(map[string]DocumentField)["at"], want: DocumentFieldTypeTime},
+		{name: "Slice of structs", field: audit["events"].Value.([]DocumentField)[0].Value.(map[string]DocumentField)["blob"], want: DocumentFieldTypeBinary},
+		{name: "Map values", field: audit["by_name"].Value.(map[string]DocumentField)["a"], want: DocumentFieldTypeTime},
+		{name: "Recursive type top level", field: doc.Fields["tree"].Value.(map[string]DocumentField)["seen"], want: DocumentFieldTypeTime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.field.Type != tt.want {
+				t.Errorf("field type = %s, want %s", tt.field.Type, tt.want)
+			}
+		})
+	}
+
+	var out Record
+	if err := UnmarshalDocument(doc, &out); err != nil {
+		t.Fatalf("UnmarshalDocument() error = %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("UnmarshalDocument() got = %+v, want %+v", out, in)
+	}
+}
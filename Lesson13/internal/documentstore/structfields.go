@@ -0,0 +1,377 @@
+package documentstore
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// jsonFieldName повертає ім'я поля структури так, як його запише encoding/json.
+// skip == true для неекспортованих полів та полів з тегом `json:"-"`.
+func jsonFieldName(sf reflect.StructField) (name string, skip bool) {
+	if !sf.IsExported() && !sf.Anonymous {
+		return "", true
+	}
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = sf.Name
+	}
+	return name, false
+}
+
+// docstoreTag - розібраний тег `docstore:"..."`
+type docstoreTag struct {
+	pk       bool
+	omit     bool
+	index    bool
+	unique   bool
+	name     string
+	compound string
+}
+
+// parseDocstoreTag розбирає тег `docstore:"..."`. Опції перелічуються через кому:
+//
+//	pk              - первинний ключ колекції; кілька полів з pk утворюють складений ключ
+//	index           - простий індекс по полю
+//	unique          - індекс унікальний; без compound означає й index
+//	compound=<ім'я> - поле входить до складеного індексу <ім'я> у порядку оголошення полів
+//	name=<поле>     - ім'я поля в документі замість json-імені
+//	-               - поле не зберігається в документі
+func parseDocstoreTag(tag string) (docstoreTag, error) {
+	var t docstoreTag
+	if tag == "" {
+		return t, nil
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		opt = strings.TrimSpace(opt)
+		key, value, hasValue := strings.Cut(opt, "=")
+		switch {
+		case opt == "-":
+			t.omit = true
+		case opt == "pk":
+			t.pk = true
+		case opt == "index":
+			t.index = true
+		case opt == "unique":
+			t.unique = true
+		case key == "compound" && hasValue && value != "":
+			t.compound = value
+		case key == "name" && hasValue && value != "":
+			t.name = value
+		default:
+			return t, fmt.Errorf("%w: unknown option '%s'", ErrInvalidStructTag, opt)
+		}
+	}
+	if t.unique && t.compound == "" {
+		t.index = true
+	}
+	if t.omit && (t.pk || t.index || t.compound != "" || t.name != "") {
+		return t, fmt.Errorf("%w: '-' cannot be combined with other options", ErrInvalidStructTag)
+	}
+	return t, nil
+}
+
+// structMeta - те, що теги структури кажуть про документ
+type structMeta struct {
+	primaryKeys []string             // ключові поля документа в порядку оголошення
+	fields      []string             // імена полів документа
+	hints       map[string]fieldHint // типи, які JSON не зберігає; за json-ім'ям
+	renames     map[string]string    // json-ім'я -> ім'я в документі
+	omitted     map[string]bool      // json-імена полів, що не зберігаються
+	indexes     []IndexOptions
+}
+
+// config повертає конфігурацію колекції з первинним ключем, описаним тегами
+func (m structMeta) config() *CollectionConfig {
+	if len(m.primaryKeys) == 1 {
+		return &CollectionConfig{PrimaryKey: m.primaryKeys[0]}
+	}
+	return &CollectionConfig{PrimaryKeys: m.primaryKeys}
+}
+
+func (m structMeta) hasField(name string) bool {
+	for _, f := range m.fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+type structMetaResult struct {
+	meta structMeta
+	err  error
+}
+
+// structMetaCache кешує розбір тегів для кожного типу
+var structMetaCache sync.Map
+
+func structMetaFor[T any]() (structMeta, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return structMeta{}, fmt.Errorf("%w: got %s", ErrNotAStruct, t)
+	}
+	return structMetaOf(t)
+}
+
+// structMetaOf повертає метадані структури; для інших типів - порожні метадані
+func structMetaOf(t reflect.Type) (structMeta, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return structMeta{}, nil
+	}
+	if cached, ok := structMetaCache.Load(t); ok {
+		r := cached.(structMetaResult)
+		return r.meta, r.err
+	}
+
+	meta := structMeta{
+		hints:   make(map[string]fieldHint),
+		renames: make(map[string]string),
+		omitted: make(map[string]bool),
+	}
+	compound := make(map[string]*IndexOptions)
+	var compoundOrder []string
+	err := collectStructMeta(t, &meta, compound, &compoundOrder)
+	if err == nil {
+		for _, name := range compoundOrder {
+			meta.indexes = append(meta.indexes, *compound[name])
+		}
+	}
+	structMetaCache.Store(t, structMetaResult{meta: meta, err: err})
+	return meta, err
+}
+
+func collectStructMeta(t reflect.Type, meta *structMeta, compound map[string]*IndexOptions, compoundOrder *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		jsonName, skip := jsonFieldName(sf)
+		if skip {
+			continue
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		// Вбудовані структури без тегу encoding/json розгортає на верхній рівень
+		if sf.Anonymous && ft.Kind() == reflect.Struct && sf.Tag.Get("json") == "" && ft != timeType {
+			if err := collectStructMeta(ft, meta, compound, compoundOrder); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag, err := parseDocstoreTag(sf.Tag.Get("docstore"))
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", sf.Name, err)
+		}
+		if tag.omit {
+			meta.omitted[jsonName] = true
+			continue
+		}
+
+		if hint, ok := typeHint(sf.Type, map[reflect.Type]bool{t: true}); ok {
+			meta.hints[jsonName] = hint
+		}
+
+		name := jsonName
+		if tag.name != "" {
+			name = tag.name
+			meta.renames[jsonName] = name
+		}
+		if meta.hasField(name) {
+			return fmt.Errorf("%w: duplicate document field '%s'", ErrInvalidStructTag, name)
+		}
+		meta.fields = append(meta.fields, name)
+
+		if tag.pk {
+			meta.primaryKeys = append(meta.primaryKeys, name)
+		}
+		if tag.index {
+			meta.indexes = append(meta.indexes, IndexOptions{Fields: []string{name}, Unique: tag.unique && tag.compound == ""})
+		}
+		if tag.compound != "" {
+			opts, ok := compound[tag.compound]
+			if !ok {
+				opts = &IndexOptions{Name: tag.compound}
+				compound[tag.compound] = opts
+				*compoundOrder = append(*compoundOrder, tag.compound)
+			}
+			opts.Fields = append(opts.Fields, name)
+			opts.Unique = opts.Unique || tag.unique
+		}
+	}
+	return nil
+}
+
+// fieldHint описує значення, тип якого JSON не зберігає: time.Time і []byte стають
+// рядками. Підказка може стосуватися самого поля (typ), полів вкладеної структури
+// (nested, за json-іменами) або елементів масиву чи значень map (elem).
+type fieldHint struct {
+	typ    DocumentFieldType
+	nested map[string]fieldHint
+	elem   *fieldHint
+}
+
+// typeHint будує підказку для типу поля; false - у значенні немає time.Time чи []byte.
+// visiting захищає від рекурсивних типів: повторно вкладена та сама структура
+// підказок не отримує.
+func typeHint(t reflect.Type, visiting map[reflect.Type]bool) (fieldHint, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return fieldHint{typ: DocumentFieldTypeTime}, true
+	case t == bytesType:
+		return fieldHint{typ: DocumentFieldTypeBinary}, true
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map:
+		if elem, ok := typeHint(t.Elem(), visiting); ok {
+			return fieldHint{elem: &elem}, true
+		}
+	case t.Kind() == reflect.Struct && !visiting[t]:
+		visiting[t] = true
+		defer delete(visiting, t)
+		nested := make(map[string]fieldHint)
+		collectNestedHints(t, visiting, nested)
+		if len(nested) > 0 {
+			return fieldHint{nested: nested}, true
+		}
+	}
+	return fieldHint{}, false
+}
+
+// collectNestedHints збирає підказки полів вкладеної структури. Теги docstore
+// на вкладених рівнях не діють, тож поля зіставляються лише за json-іменами.
+func collectNestedHints(t reflect.Type, visiting map[reflect.Type]bool, nested map[string]fieldHint) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, skip := jsonFieldName(sf)
+		if skip {
+			continue
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && ft.Kind() == reflect.Struct && sf.Tag.Get("json") == "" && ft != timeType {
+			if !visiting[ft] {
+				visiting[ft] = true
+				collectNestedHints(ft, visiting, nested)
+				delete(visiting, ft)
+			}
+			continue
+		}
+		if hint, ok := typeHint(sf.Type, visiting); ok {
+			nested[name] = hint
+		}
+	}
+}
+
+// apply перетворює рядки, якими JSON записав time.Time і []byte, на поля
+// відповідних типів на будь-якій глибині
+func (h fieldHint) apply(field DocumentField) (DocumentField, error) {
+	switch {
+	case h.typ != "":
+		if s, ok := field.Value.(string); ok && field.Type == DocumentFieldTypeString {
+			return hintedField(h.typ, s)
+		}
+	case h.nested != nil && field.Type == DocumentFieldTypeObject:
+		return applyToChildren(field, func(name string) (fieldHint, bool) {
+			hint, ok := h.nested[name]
+			return hint, ok
+		})
+	case h.elem != nil && field.Type == DocumentFieldTypeObject:
+		return applyToChildren(field, func(string) (fieldHint, bool) { return *h.elem, true })
+	case h.elem != nil && field.Type == DocumentFieldTypeArray:
+		items, ok := field.Value.([]DocumentField)
+		if !ok {
+			return field, nil
+		}
+		out := make([]DocumentField, len(items))
+		for i, item := range items {
+			var err error
+			if out[i], err = h.elem.apply(item); err != nil {
+				return field, fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return DocumentField{Type: DocumentFieldTypeArray, Value: out}, nil
+	}
+	return field, nil
+}
+
+func applyToChildren(field DocumentField, hintFor func(name string) (fieldHint, bool)) (DocumentField, error) {
+	children, ok := field.Value.(map[string]DocumentField)
+	if !ok {
+		return field, nil
+	}
+	out := make(map[string]DocumentField, len(children))
+	for name, child := range children {
+		if hint, ok := hintFor(name); ok {
+			var err error
+			if child, err = hint.apply(child); err != nil {
+				return field, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		out[name] = child
+	}
+	return DocumentField{Type: DocumentFieldTypeObject, Value: out}, nil
+}
+
+// toDocumentNames перейменовує ключі верхнього рівня з json-імен на імена документа
+// та прибирає поля, позначені `docstore:"-"`
+func (m structMeta) toDocumentNames(fields map[string]DocumentField) map[string]DocumentField {
+	if len(m.renames) == 0 && len(m.omitted) == 0 {
+		return fields
+	}
+	out := make(map[string]DocumentField, len(fields))
+	for k, v := range fields {
+		if m.omitted[k] {
+			continue
+		}
+		if renamed, ok := m.renames[k]; ok {
+			k = renamed
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// toJSONNames - зворотне перетворення для UnmarshalDocument
+func (m structMeta) toJSONNames(values map[string]any) map[string]any {
+	if len(m.renames) == 0 && len(m.omitted) == 0 {
+		return values
+	}
+	inverse := make(map[string]string, len(m.renames))
+	for jsonName, docName := range m.renames {
+		inverse[docName] = jsonName
+	}
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		if jsonName, ok := inverse[k]; ok {
+			out[jsonName] = v
+			continue
+		}
+		if _, renamedAway := m.renames[k]; renamedAway || m.omitted[k] {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
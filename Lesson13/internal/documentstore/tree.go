@@ -0,0 +1,105 @@
+package documentstore
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// SkipChildren можна повернути з WalkFunc, щоб не заходити всередину поточного об'єкта чи масиву
+var SkipChildren = errors.New("skip children")
+
+// WalkFunc викликається для кожного поля дерева документа.
+// path - шлях до поля через крапку; індекси масивів записуються числами ("tags.0").
+type WalkFunc func(path string, field DocumentField) error
+
+// Walk обходить усі поля документа в глибину, ключі - в алфавітному порядку
+func (d Document) Walk(fn WalkFunc) error {
+	return walkFields("", d.Fields, fn)
+}
+
+// Walk обходить поле та всі вкладені в нього значення
+func (f DocumentField) Walk(path string, fn WalkFunc) error {
+	err := fn(path, f)
+	if errors.Is(err, SkipChildren) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch v := f.Value.(type) {
+	case map[string]DocumentField:
+		return walkFields(path, v, fn)
+	case []DocumentField:
+		for i, item := range v {
+			if err := item.Walk(joinPath(path, strconv.Itoa(i)), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func walkFields(prefix string, fields map[string]DocumentField, fn WalkFunc) error {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fields[k].Walk(joinPath(prefix, k), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Interface перетворює поле на звичайне Go-значення:
+// object -> map[string]any, array -> []any, решта типів - як є.
+func (f DocumentField) Interface() any {
+	switch v := f.Value.(type) {
+	case map[string]DocumentField:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = item.Interface()
+		}
+		return m
+	case []DocumentField:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = item.Interface()
+		}
+		return items
+	default:
+		return f.Value
+	}
+}
+
+// ToMap перетворює документ на map[string]any
+func (d Document) ToMap() map[string]any {
+	m := make(map[string]any, len(d.Fields))
+	for k, f := range d.Fields {
+		m[k] = f.Interface()
+	}
+	return m
+}
+
+// DocumentFromMap будує документ з map[string]any, визначаючи тип кожного значення рекурсивно
+func DocumentFromMap(m map[string]any) (*Document, error) {
+	doc := &Document{Fields: make(map[string]DocumentField, len(m))}
+	for k, v := range m {
+		field, err := NewDocumentField(v)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %w", k, err)
+		}
+		doc.Fields[k] = field
+	}
+	return doc, nil
+}
+
+// NewDocumentField будує поле з Go-значення (string, числа Go, json.Number, bool, nil,
+// time.Time, []byte, []any, map[string]any)
+func NewDocumentField(value any) (DocumentField, error) {
+	return toDocumentField(value)
+}
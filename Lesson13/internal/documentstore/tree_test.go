@@ -0,0 +1,126 @@
+package documentstore
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDocument_Walk(t *testing.T) {
+	doc, err := DocumentFromMap(map[string]any{
+		"id":   "1",
+		"tags": []any{"a", json.Number("2")},
+		"address": map[string]any{
+			"city": "Kyiv",
+			"geo":  map[string]any{"lat": 50.45},
+		},
+	})
+	if err != nil {
+		t.Fatalf("DocumentFromMap() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		skip string
+		want []string
+	}{
+		{
+			name: "Full walk",
+			want: []string{
+				"address:object", "address.city:string", "address.geo:object", "address.geo.lat:number",
+				"id:string", "tags:array", "tags.0:string", "tags.1:integer",
+			},
+		},
+		{
+			name: "Skip children",
+			skip: "address",
+			want: []string{"address:object", "id:string", "tags:array", "tags.0:string", "tags.1:integer"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := doc.Walk(func(path string, field DocumentField) error {
+				got = append(got, path+":"+string(field.Type))
+				if path == tt.skip {
+					return SkipChildren
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Walk() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_ToMap(t *testing.T) {
+	in := map[string]any{
+		"id":      "1",
+		"tags":    []any{"a", "b"},
+		"address": map[string]any{"city": "Kyiv", "zip": nil},
+	}
+	doc, err := DocumentFromMap(in)
+	if err != nil {
+		t.Fatalf("DocumentFromMap() error = %v", err)
+	}
+	if got := doc.ToMap(); !reflect.DeepEqual(got, in) {
+		t.Errorf("ToMap() got = %v, want %v", got, in)
+	}
+	if _, err := DocumentFromMap(map[string]any{"bad": map[string]any{"ch": make(chan int)}}); err == nil {
+		t.Errorf("DocumentFromMap() expected error for unsupported nested value")
+	}
+}
+
+func TestNewDocumentField_GoNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  DocumentField
+	}{
+		{name: "int", value: 5, want: DocumentField{Type: DocumentFieldTypeInteger, Value: int64(5)}},
+		{name: "uint8", value: uint8(7), want: DocumentField{Type: DocumentFieldTypeInteger, Value: int64(7)}},
+		{name: "uint64 above int64", value: uint64(math.MaxUint64), want: DocumentField{Type: DocumentFieldTypeDecimal, Value: json.Number("18446744073709551615")}},
+		{name: "float32", value: float32(1.5), want: DocumentField{Type: DocumentFieldTypeNumber, Value: 1.5}},
+		{name: "Nested int", value: map[string]any{"n": []any{int32(3)}}, want: DocumentField{Type: DocumentFieldTypeObject, Value: map[string]DocumentField{
+			"n": {Type: DocumentFieldTypeArray, Value: []DocumentField{{Type: DocumentFieldTypeInteger, Value: int64(3)}}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDocumentField(tt.value)
+			if err != nil {
+				t.Fatalf("NewDocumentField() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewDocumentField() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	doc, err := DocumentFromMap(map[string]any{"n": 5, "ratio": float32(0.5)})
+	if err != nil {
+		t.Fatalf("DocumentFromMap() error = %v", err)
+	}
+	if got := doc.ToMap(); !reflect.DeepEqual(got, map[string]any{"n": int64(5), "ratio": 0.5}) {
+		t.Errorf("ToMap() got = %v", got)
+	}
+}
+
+func TestDocumentField_UnmarshalLegacyNested(t *testing.T) {
+	var got DocumentField
+	if err := json.Unmarshal([]byte(`{"type":"object","value":{"city":"Kyiv","zip":1001}}`), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := DocumentField{Type: DocumentFieldTypeObject, Value: map[string]DocumentField{
+		"city": {Type: DocumentFieldTypeString, Value: "Kyiv"},
+		"zip":  {Type: DocumentFieldTypeInteger, Value: int64(1001)},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,139 @@
+package documentstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+var ErrInvalidExpiry = errors.New("invalid expiry configuration")
+
+// timeNow - годинник для обчислення та перевірки терміну дії; тести підміняють його
+var timeNow = time.Now
+
+// checkExpiry перевіряє TTL та ExpiresAtField конфігурації
+func (cfg *CollectionConfig) checkExpiry() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.TTL < 0 {
+		return fmt.Errorf("%w: negative TTL %s", ErrInvalidExpiry, cfg.TTL)
+	}
+	for _, name := range cfg.keyFields() {
+		if cfg.ExpiresAtField != "" && cfg.ExpiresAtField == name {
+			return fmt.Errorf("%w: primary key field '%s' cannot hold expiry time", ErrInvalidExpiry, name)
+		}
+	}
+	return nil
+}
+
+// expiresAt обчислює термін дії документа при записі: час з поля ExpiresAtField,
+// якщо воно є (time або рядок RFC3339), інакше now + TTL. nil - документ не старіє.
+func (c *Collection) expiresAt(doc Document, now time.Time) (*time.Time, error) {
+	if name := c.config.ExpiresAtField; name != "" {
+		if field, ok := doc.Fields[name]; ok && field.Type != DocumentFieldTypeNull {
+			t, err := fieldTime(field)
+			if err != nil {
+				return nil, fmt.Errorf("%w: field '%s': %v", ErrInvalidExpiry, name, err)
+			}
+			return &t, nil
+		}
+	}
+	if c.config.TTL > 0 {
+		t := now.Add(c.config.TTL)
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func fieldTime(field DocumentField) (time.Time, error) {
+	switch v := field.Value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	}
+	return time.Time{}, fmt.Errorf("expected time, got '%s'", field.Type)
+}
+
+// expired повідомляє, чи минув термін дії документа на момент now
+func (d Document) expired(now time.Time) bool {
+	return d.ExpiresAt != nil && !now.Before(*d.ExpiresAt)
+}
+
+// liveLocked повертає документ, якщо він є і ще не прострочений.
+// Прострочені документи лишаються в колекції до DeleteExpired, але для
+// читання та запису вважаються відсутніми. Викликається під c.mu.
+func (c *Collection) liveLocked(key string) (Document, bool) {
+	doc, ok := c.documents[key]
+	if !ok || doc.expired(timeNow()) {
+		return Document{}, false
+	}
+	return doc, true
+}
+
+// DeleteExpired видаляє прострочені документи з колекції та індексів
+// і повертає кількість видалених
+func (c *Collection) DeleteExpired() int {
+	c.mu.Lock()
+	defer c.unlockAndRunHooks()
+
+	now := timeNow()
+	removed := make(map[string]Document)
+	for key, doc := range c.documents {
+		if doc.expired(now) {
+			c.publishLocked(deleteEvent(ChangeExpire, key, doc))
+			removed[key] = Document{}
+			delete(c.documents, key)
+			c.untrackLocked(key)
+		}
+	}
+	if len(removed) > 0 {
+		c.mergeIndexes(nil, removed)
+		slog.Debug("DeleteExpired: documents removed", slog.Int("removed", len(removed)))
+	}
+	return len(removed)
+}
+
+// DeleteExpired видаляє прострочені документи в усіх колекціях
+func (s *Store) DeleteExpired() int {
+	s.mu.RLock()
+	collections := make([]*Collection, 0, len(s.collections))
+	for _, c := range s.collections {
+		collections = append(collections, c)
+	}
+	s.mu.RUnlock()
+
+	removed := 0
+	for _, c := range collections {
+		removed += c.DeleteExpired()
+	}
+	return removed
+}
+
+// StartSweeper запускає фонове видалення прострочених документів кожні interval
+// і зупиняє його, коли ctx скасовано. Якщо interval <= 0, фонове видалення
+// не запускається і повертається ErrInvalidExpiry.
+func (s *Store) StartSweeper(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: non-positive sweep interval %s", ErrInvalidExpiry, interval)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("EXPIRY SWEEPER STOPPED", slog.String("message", "Фонове видалення прострочених документів зупинено"))
+				return
+			case <-ticker.C:
+				if removed := s.DeleteExpired(); removed > 0 {
+					slog.Info("EXPIRED DOCUMENTS REMOVED", slog.Int("removed", removed), slog.String("message", fmt.Sprintf("Видалено прострочених документів: %d", removed)))
+				}
+			}
+		}
+	}()
+	return nil
+}
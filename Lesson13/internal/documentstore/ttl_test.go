@@ -0,0 +1,182 @@
+package documentstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// setClock підміняє timeNow на керований годинник до кінця тесту
+func setClock(t *testing.T, start time.Time) *time.Time {
+	t.Helper()
+	now := start
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+	return &now
+}
+
+func TestCollection_TTLHidesExpiredDocuments(t *testing.T) {
+	now := setClock(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id", TTL: time.Hour}}
+	if err := c.CreateIndexWithOptions(IndexOptions{Fields: []string{"email"}, Unique: true}); err != nil {
+		t.Fatalf("CreateIndexWithOptions() error = %v", err)
+	}
+	if _, _, err := c.Put(txDoc("1", map[string]any{"email": "a@x"})); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	*now = now.Add(30 * time.Minute)
+	if _, _, err := c.Put(txDoc("2", map[string]any{"email": "b@x"})); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// Документ "1" прострочено, "2" - ще ні; sweeper ще не запускався
+	*now = now.Add(45 * time.Minute)
+	if _, err := c.Get("1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Get() expired error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+	if _, err := c.Get("2"); err != nil {
+		t.Errorf("Get() live error = %v", err)
+	}
+	if got := c.NumDocuments(); got != 1 {
+		t.Errorf("NumDocuments() = %d, want 1", got)
+	}
+	if got := len(c.List()); got != 1 {
+		t.Errorf("List() len = %d, want 1", got)
+	}
+	if docs, _ := c.Query("email", QueryParams{}); len(docs) != 1 {
+		t.Errorf("Query() len = %d, want 1", len(docs))
+	}
+	if docs, _ := c.Scan(KeyRange{}); len(docs) != 1 {
+		t.Errorf("Scan() len = %d, want 1", len(docs))
+	}
+	if err := c.Delete("1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Delete() expired error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+
+	// Значення унікального індексу звільняється, а Insert бачить ключ вільним
+	if _, err := c.Insert(txDoc("1", map[string]any{"email": "c@x"})); err != nil {
+		t.Errorf("Insert() over expired document error = %v", err)
+	}
+	if _, _, err := c.Put(txDoc("3", map[string]any{"email": "a@x"})); err != nil {
+		t.Errorf("Put() with expired unique value error = %v", err)
+	}
+}
+
+func TestCollection_DeleteExpired(t *testing.T) {
+	now := setClock(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id", ExpiresAtField: "expiresAt"}}
+	if err := c.CreateIndex("expiresAt"); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	docs := []Document{
+		txDoc("soon", map[string]any{"expiresAt": now.Add(time.Minute)}),
+		txDoc("later", map[string]any{"expiresAt": now.Add(time.Hour).Format(time.RFC3339)}),
+		txDoc("never", nil),
+	}
+	if _, err := c.PutMany(docs, BatchAllOrNothing); err != nil {
+		t.Fatalf("PutMany() error = %v", err)
+	}
+	if _, _, err := c.Put(txDoc("bad", map[string]any{"expiresAt": 42})); !errors.Is(err, ErrInvalidExpiry) {
+		t.Errorf("Put() with invalid expiry error = %v, wantErr %v", err, ErrInvalidExpiry)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	if removed := c.DeleteExpired(); removed != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", removed)
+	}
+	c.mu.RLock()
+	_, stillStored := c.documents["soon"]
+	indexed := len(c.indexes["expiresAt"].Sorted)
+	c.mu.RUnlock()
+	if stillStored || indexed != 1 {
+		t.Errorf("after DeleteExpired() stored = %v, indexed = %d, want false, 1", stillStored, indexed)
+	}
+	if got := c.NumDocuments(); got != 2 {
+		t.Errorf("NumDocuments() = %d, want 2", got)
+	}
+}
+
+func TestStore_DumpKeepsExpiry(t *testing.T) {
+	now := setClock(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := NewStore()
+	if err := s.CreateCollection("sessions", &CollectionConfig{PrimaryKey: "id", TTL: time.Hour}); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	sessions, _ := s.GetCollection("sessions")
+	if _, _, err := sessions.Put(txDoc("1", nil)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	dump, err := s.Dump()
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	restored, err := NewStoreFromDump(dump)
+	if err != nil {
+		t.Fatalf("NewStoreFromDump() error = %v", err)
+	}
+	sessions, _ = restored.GetCollection("sessions")
+	if _, err := sessions.Get("1"); err != nil {
+		t.Fatalf("Get() before expiry error = %v", err)
+	}
+	*now = now.Add(time.Hour)
+	if _, err := sessions.Get("1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Get() after expiry error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+	if removed := restored.DeleteExpired(); removed != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", removed)
+	}
+}
+
+func TestStore_StartSweeper(t *testing.T) {
+	s := NewStore()
+	if err := s.CreateCollection("tokens", &CollectionConfig{PrimaryKey: "id", TTL: time.Millisecond}); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	tokens, _ := s.GetCollection("tokens")
+	if _, _, err := tokens.Put(txDoc("1", nil)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := s.StartSweeper(ctx, interval); !errors.Is(err, ErrInvalidExpiry) {
+			t.Errorf("StartSweeper(%s) error = %v, wantErr %v", interval, err, ErrInvalidExpiry)
+		}
+	}
+	if err := s.StartSweeper(ctx, time.Millisecond); err != nil {
+		t.Fatalf("StartSweeper() error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		tokens.mu.RLock()
+		left := len(tokens.documents)
+		tokens.mu.RUnlock()
+		if left == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sweeper did not remove expired document")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStore_CreateCollectionInvalidExpiry(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *CollectionConfig
+	}{
+		{name: "Negative TTL", cfg: &CollectionConfig{PrimaryKey: "id", TTL: -time.Second}},
+		{name: "Expiry in primary key", cfg: &CollectionConfig{PrimaryKey: "id", ExpiresAtField: "id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewStore().CreateCollection("c", tt.cfg); !errors.Is(err, ErrInvalidExpiry) {
+				t.Errorf("CreateCollection() error = %v, wantErr %v", err, ErrInvalidExpiry)
+			}
+		})
+	}
+}
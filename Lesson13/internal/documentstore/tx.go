@@ -0,0 +1,292 @@
+package documentstore
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+var (
+	ErrTxConflict = errors.New("transaction conflict")
+	ErrTxClosed   = errors.New("transaction is already committed or rolled back")
+)
+
+// Tx - оптимістична транзакція над кількома колекціями Store.
+//
+// Put і Delete лише буферизуються; Get бачить власні незакомічені зміни.
+// Для кожного документа, якого торкнулася транзакція, запам'ятовується версія
+// на момент першого звернення. Commit блокує всі задіяні колекції, перевіряє,
+// що жодна з цих версій не змінилася (інакше ErrTxConflict), і застосовує
+// зміни разом з індексами. Якщо застосувати зміну не вдалося (наприклад,
+// через унікальний індекс), уже застосовані зміни відкочуються.
+//
+// Tx не призначена для одночасного використання з кількох горутин.
+type Tx struct {
+	store  *Store
+	colls  map[string]*Collection
+	reads  map[txKey]txRead
+	writes map[txKey]txWrite
+	order  []txKey // порядок першого запису кожного ключа
+	closed bool
+}
+
+type txKey struct {
+	collection string
+	key        string
+}
+
+type txRead struct {
+	exists  bool
+	version uint64
+}
+
+type txWrite struct {
+	doc     Document
+	deleted bool
+}
+
+// Begin починає нову транзакцію
+func (s *Store) Begin() *Tx {
+	return &Tx{
+		store:  s,
+		colls:  make(map[string]*Collection),
+		reads:  make(map[txKey]txRead),
+		writes: make(map[txKey]txWrite),
+	}
+}
+
+func (tx *Tx) collection(name string) (*Collection, error) {
+	if tx.closed {
+		return nil, ErrTxClosed
+	}
+	if c, ok := tx.colls[name]; ok {
+		return c, nil
+	}
+	c, err := tx.store.GetCollection(name)
+	if err != nil {
+		return nil, err
+	}
+	if c.config == nil {
+		return nil, errors.New("collection config is not initialized")
+	}
+	tx.colls[name] = c
+	return c, nil
+}
+
+// observeLocked запам'ятовує версію документа при першому зверненні.
+// Викликається під c.mu (читання або запис).
+func (tx *Tx) observeLocked(c *Collection, k txKey) (Document, bool) {
+	doc, ok := c.liveLocked(k.key)
+	if _, seen := tx.reads[k]; !seen {
+		tx.reads[k] = txRead{exists: ok, version: doc.Version}
+	}
+	return doc, ok
+}
+
+// Get повертає документ з урахуванням змін, зроблених у цій транзакції
+func (tx *Tx) Get(collection string, keyValues ...any) (*Document, error) {
+	c, err := tx.collection(collection)
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.lookupKey(keyValues)
+	if err != nil {
+		return nil, err
+	}
+	k := txKey{collection: collection, key: key}
+	if w, ok := tx.writes[k]; ok {
+		if w.deleted {
+			return nil, ErrDocumentNotFound
+		}
+		doc := w.doc
+		return &doc, nil
+	}
+
+	c.mu.RLock()
+	doc, ok := tx.observeLocked(c, k)
+	c.mu.RUnlock()
+	if !ok {
+		return nil, ErrDocumentNotFound
+	}
+	return &doc, nil
+}
+
+// Put додає запис документа до транзакції та повертає його ключ.
+// Ключ генерується й схема перевіряється одразу, як у Collection.Put.
+func (tx *Tx) Put(collection string, doc Document) (string, error) {
+	c, err := tx.collection(collection)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	doc, err = c.assignKey(doc)
+	if err == nil {
+		err = c.validate(doc)
+	}
+	var key string
+	if err == nil {
+		key, err = c.documentKey(doc)
+	}
+	if err == nil {
+		tx.observeLocked(c, txKey{collection: collection, key: key})
+	}
+	c.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	tx.write(txKey{collection: collection, key: key}, txWrite{doc: doc})
+	return key, nil
+}
+
+// Delete додає видалення документа до транзакції
+func (tx *Tx) Delete(collection string, keyValues ...any) error {
+	if _, err := tx.Get(collection, keyValues...); err != nil {
+		return err
+	}
+	c := tx.colls[collection]
+	key, _ := c.lookupKey(keyValues) // ключ уже перевірено в Get
+	tx.write(txKey{collection: collection, key: key}, txWrite{deleted: true})
+	return nil
+}
+
+func (tx *Tx) write(k txKey, w txWrite) {
+	if _, ok := tx.writes[k]; !ok {
+		tx.order = append(tx.order, k)
+	}
+	tx.writes[k] = w
+}
+
+// Rollback скасовує транзакцію. Повторний виклик повертає ErrTxClosed.
+func (tx *Tx) Rollback() error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+	tx.closed = true
+	tx.writes, tx.reads, tx.order = nil, nil, nil
+	return nil
+}
+
+// txUndo - стан документа до застосування запису транзакції
+type txUndo struct {
+	c       *Collection
+	key     string
+	prev    Document
+	existed bool
+}
+
+// Commit атомарно застосовує всі зміни транзакції
+func (tx *Tx) Commit() error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+	tx.closed = true
+
+	// Колекції блокуються в порядку імен, щоб паралельні Commit не заблокували одне одного
+	names := make([]string, 0, len(tx.colls))
+	for name := range tx.colls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tx.store.mu.RLock()
+	for _, name := range names {
+		if tx.store.collections[name] != tx.colls[name] {
+			tx.store.mu.RUnlock()
+			return fmt.Errorf("%w: collection '%s' was deleted or recreated", ErrTxConflict, name)
+		}
+	}
+	for _, name := range names {
+		tx.colls[name].mu.Lock()
+	}
+	// After-хуки викликаються, коли Store і всі колекції транзакції вже розблоковано
+	defer func() {
+		var after []func()
+		for _, name := range names {
+			c := tx.colls[name]
+			events, hooks := c.afterHooksLocked()
+			c.mu.Unlock()
+			after = append(after, func() { runAfterHooks(events, hooks) })
+		}
+		tx.store.mu.RUnlock()
+		for _, run := range after {
+			run()
+		}
+	}()
+
+	for k, r := range tx.reads {
+		doc, ok := tx.colls[k.collection].liveLocked(k.key)
+		if ok != r.exists || doc.Version != r.version {
+			slog.Warn("TX CONFLICT", slog.String("collection", k.collection), slog.String("key", k.key), slog.String("message", fmt.Sprintf("Документ '%s' у колекції '%s' змінено іншим записом", k.key, k.collection)))
+			return fmt.Errorf("%w: document '%s' in collection '%s' was modified", ErrTxConflict, k.key, k.collection)
+		}
+	}
+
+	undo := make([]txUndo, 0, len(tx.order))
+	events := make([]ChangeEvent, 0, len(tx.order))
+	for _, k := range tx.order {
+		c := tx.colls[k.collection]
+		w := tx.writes[k]
+		prev, existed := c.documents[k.key]
+		if c.documents == nil {
+			c.documents = make(map[string]Document)
+		}
+		_, live := c.liveLocked(k.key)
+
+		var ev ChangeEvent
+		var err error
+		switch {
+		case w.deleted && live:
+			if err = c.beforeDeleteLocked(k.key); err == nil {
+				var removed Document
+				removed, err = c.removeLocked(k.key)
+				ev = deleteEvent(ChangeDelete, k.key, removed)
+			}
+		case w.deleted:
+			continue // документ створено й видалено в межах транзакції
+		default:
+			var doc Document
+			if doc, err = c.beforePutLocked(ChangePut, k.key, w.doc); err == nil {
+				ev, err = c.storeLocked(k.key, doc)
+			}
+		}
+		if err != nil {
+			tx.undo(undo)
+			slog.Warn("TX ROLLED BACK", slog.Any("error", err), slog.String("message", "Транзакцію відкочено"))
+			return err
+		}
+		undo = append(undo, txUndo{c: c, key: k.key, prev: prev, existed: existed})
+		events = append(events, ev)
+	}
+
+	// Підписники бачать зміни транзакції лише після успішного застосування
+	for i, u := range undo {
+		u.c.publishLocked(events[i])
+	}
+	for _, name := range names {
+		tx.colls[name].evictLocked("")
+	}
+
+	slog.Debug("TX COMMITTED", slog.Int("writes", len(tx.order)), slog.String("message", "Транзакцію застосовано"))
+	return nil
+}
+
+// undo повертає документи й індекси до стану перед Commit
+func (tx *Tx) undo(applied []txUndo) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		u := applied[i]
+		if u.existed {
+			u.c.documents[u.key] = u.prev
+			u.c.updateIndexes(u.key, u.prev)
+			u.c.trackLocked(u.key, u.prev)
+			continue
+		}
+		if doc, ok := u.c.documents[u.key]; ok {
+			delete(u.c.documents, u.key)
+			u.c.removeFromIndexes(u.key, doc)
+			u.c.untrackLocked(u.key)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package documentstore
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func txDoc(id string, fields map[string]any) Document {
+	doc := Document{Fields: map[string]DocumentField{"id": {Type: DocumentFieldTypeString, Value: id}}}
+	for k, v := range fields {
+		f, _ := keyValueFields([]any{v})
+		doc.Fields[k] = f[0]
+	}
+	return doc
+}
+
+func newTxStore(t *testing.T, names ...string) *Store {
+	t.Helper()
+	s := NewStore()
+	for _, name := range names {
+		if err := s.CreateCollection(name, &CollectionConfig{PrimaryKey: "id"}); err != nil {
+			t.Fatalf("CreateCollection() error = %v", err)
+		}
+	}
+	return s
+}
+
+func TestTx_MoveBetweenCollections(t *testing.T) {
+	s := newTxStore(t, "active", "archive")
+	active, _ := s.GetCollection("active")
+	archive, _ := s.GetCollection("archive")
+	if _, _, err := active.Put(txDoc("1", map[string]any{"name": "Alice"})); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	tx := s.Begin()
+	doc, err := tx.Get("active", "1")
+	if err != nil {
+		t.Fatalf("Tx.Get() error = %v", err)
+	}
+	if _, err := tx.Put("archive", *doc); err != nil {
+		t.Fatalf("Tx.Put() error = %v", err)
+	}
+	if err := tx.Delete("active", "1"); err != nil {
+		t.Fatalf("Tx.Delete() error = %v", err)
+	}
+
+	// До Commit зміни видно лише всередині транзакції
+	if _, err := tx.Get("active", "1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Tx.Get() deleted document error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+	if _, err := archive.Get("1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("archive.Get() before Commit error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if _, err := active.Get("1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("active.Get() after Commit error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+	if got, err := archive.Get("1"); err != nil || got.Fields["name"].Value != "Alice" {
+		t.Errorf("archive.Get() after Commit got = %v, %v", got, err)
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxClosed) {
+		t.Errorf("second Commit() error = %v, wantErr %v", err, ErrTxClosed)
+	}
+}
+
+func TestTx_Conflict(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(tx *Tx) error
+		race  func(c *Collection) error
+	}{
+		{
+			name:  "Read document changed",
+			setup: func(tx *Tx) error { _, err := tx.Get("users", "1"); return err },
+			race:  func(c *Collection) error { _, _, err := c.Put(txDoc("1", map[string]any{"name": "Bob"})); return err },
+		},
+		{
+			name:  "Written document changed",
+			setup: func(tx *Tx) error { _, err := tx.Put("users", txDoc("1", map[string]any{"name": "Carol"})); return err },
+			race:  func(c *Collection) error { _, _, err := c.Put(txDoc("1", map[string]any{"name": "Bob"})); return err },
+		},
+		{
+			name:  "Absent document created",
+			setup: func(tx *Tx) error { _, err := tx.Put("users", txDoc("2", nil)); return err },
+			race:  func(c *Collection) error { _, _, err := c.Put(txDoc("2", nil)); return err },
+		},
+		{
+			name:  "Deleted document removed",
+			setup: func(tx *Tx) error { return tx.Delete("users", "1") },
+			race:  func(c *Collection) error { return c.Delete("1") },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTxStore(t, "users", "log")
+			users, _ := s.GetCollection("users")
+			if _, _, err := users.Put(txDoc("1", map[string]any{"name": "Alice"})); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+
+			tx := s.Begin()
+			if err := tt.setup(tx); err != nil {
+				t.Fatalf("setup error = %v", err)
+			}
+			if _, err := tx.Put("log", txDoc("entry", nil)); err != nil {
+				t.Fatalf("Tx.Put() error = %v", err)
+			}
+			if err := tt.race(users); err != nil {
+				t.Fatalf("race error = %v", err)
+			}
+			if err := tx.Commit(); !errors.Is(err, ErrTxConflict) {
+				t.Errorf("Commit() error = %v, wantErr %v", err, ErrTxConflict)
+			}
+			log, _ := s.GetCollection("log")
+			if log.NumDocuments() != 0 {
+				t.Errorf("Commit() with conflict applied writes to other collections")
+			}
+		})
+	}
+}
+
+func TestTx_CommitRollsBackOnUniqueViolation(t *testing.T) {
+	s := newTxStore(t, "users")
+	users, _ := s.GetCollection("users")
+	if err := users.CreateIndexWithOptions(IndexOptions{Fields: []string{"email"}, Unique: true}); err != nil {
+		t.Fatalf("CreateIndexWithOptions() error = %v", err)
+	}
+	if _, _, err := users.Put(txDoc("1", map[string]any{"email": "a@x"})); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	tx := s.Begin()
+	// Перший запис змінює наявний документ, другий - порушує унікальність
+	if _, err := tx.Put("users", txDoc("1", map[string]any{"email": "b@x"})); err != nil {
+		t.Fatalf("Tx.Put() error = %v", err)
+	}
+	if _, err := tx.Put("users", txDoc("2", map[string]any{"email": "b@x"})); err != nil {
+		t.Fatalf("Tx.Put() error = %v", err)
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("Commit() error = %v, wantErr %v", err, ErrUniqueViolation)
+	}
+
+	got, _ := users.Get("1")
+	if got.Fields["email"].Value != "a@x" {
+		t.Errorf("document after rollback = %v, want email a@x", got)
+	}
+	if _, err := users.Get("2"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Get() after rollback error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+	docs, _ := users.Query("email", QueryParams{})
+	if len(docs) != 1 || docs[0].Fields["email"].Value != "a@x" {
+		t.Errorf("index after rollback = %v, want only a@x", docs)
+	}
+}
+
+func TestTx_Rollback(t *testing.T) {
+	s := newTxStore(t, "users")
+	tx := s.Begin()
+	if _, err := tx.Put("users", txDoc("1", nil)); err != nil {
+		t.Fatalf("Tx.Put() error = %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	users, _ := s.GetCollection("users")
+	if users.NumDocuments() != 0 {
+		t.Errorf("Rollback() left %d documents", users.NumDocuments())
+	}
+	if _, err := tx.Put("users", txDoc("1", nil)); !errors.Is(err, ErrTxClosed) {
+		t.Errorf("Tx.Put() after Rollback() error = %v, wantErr %v", err, ErrTxClosed)
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxClosed) {
+		t.Errorf("Commit() after Rollback() error = %v, wantErr %v", err, ErrTxClosed)
+	}
+}
+
+func TestTx_ConcurrentTransfers(t *testing.T) {
+	s := newTxStore(t, "accounts")
+	accounts, _ := s.GetCollection("accounts")
+	for _, id := range []string{"a", "b"} {
+		if _, _, err := accounts.Put(txDoc(id, map[string]any{"balance": 100})); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	transfer := func(from, to string) error {
+		for {
+			tx := s.Begin()
+			src, err := tx.Get("accounts", from)
+			if err != nil {
+				return err
+			}
+			dst, err := tx.Get("accounts", to)
+			if err != nil {
+				return err
+			}
+			tx.Put("accounts", txDoc(from, map[string]any{"balance": src.Fields["balance"].Value.(int64) - 1}))
+			tx.Put("accounts", txDoc(to, map[string]any{"balance": dst.Fields["balance"].Value.(int64) + 1}))
+			err = tx.Commit()
+			if !errors.Is(err, ErrTxConflict) {
+				return err
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			from, to := "a", "b"
+			if i%2 == 1 {
+				from, to = to, from
+			}
+			for j := 0; j < 25; j++ {
+				if err := transfer(from, to); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	a, _ := accounts.Get("a")
+	b, _ := accounts.Get("b")
+	if total := a.Fields["balance"].Value.(int64) + b.Fields["balance"].Value.(int64); total != 200 {
+		t.Errorf("total balance = %d, want 200", total)
+	}
+}
@@ -0,0 +1,164 @@
+package documentstore
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+var (
+	ErrNotAStruct          = errors.New("typed collection requires a struct type")
+	ErrPrimaryKeyMismatch  = errors.New("struct primary key does not match collection primary key")
+	ErrPrimaryKeyUndefined = errors.New("struct has no primary key field")
+	ErrInvalidStructTag    = errors.New("invalid docstore struct tag")
+)
+
+// TypedCollection - обгортка над Collection, яка сама виконує
+// MarshalDocument/UnmarshalDocument для значень типу T.
+// Первинний ключ береться з тегу `docstore:"pk"` на полі структури (див. parseDocstoreTag).
+type TypedCollection[T any] struct {
+	coll        *Collection
+	primaryKeys []string
+}
+
+// NewTypedCollection перевіряє, що ключові поля T відповідають первинному ключу колекції.
+// Якщо тегу pk немає, використовуються поля з json-іменами ключових полів колекції.
+func NewTypedCollection[T any](coll *Collection) (*TypedCollection[T], error) {
+	if coll == nil || coll.config == nil {
+		return nil, errors.New("collection config is not initialized")
+	}
+	meta, err := structMetaFor[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	want := coll.config.keyFields()
+	pk := meta.primaryKeys
+	switch {
+	case len(pk) == 0:
+		for _, f := range want {
+			if !meta.hasField(f) {
+				return nil, fmt.Errorf("%w: '%s'", ErrPrimaryKeyUndefined, f)
+			}
+		}
+		pk = want
+	case !slices.Equal(pk, want):
+		return nil, fmt.Errorf("%w: struct %v, collection %v", ErrPrimaryKeyMismatch, pk, want)
+	}
+	return &TypedCollection[T]{coll: coll, primaryKeys: pk}, nil
+}
+
+// Collection повертає нетипізовану колекцію
+func (tc *TypedCollection[T]) Collection() *Collection {
+	return tc.coll
+}
+
+// Put зберігає значення та повертає ключ документа (згенерований, якщо ключове поле порожнє)
+func (tc *TypedCollection[T]) Put(value T) (string, error) {
+	doc, err := MarshalDocument(value)
+	if err != nil {
+		return "", err
+	}
+	key, _, err := tc.coll.Put(*doc)
+	return key, err
+}
+
+// Insert зберігає нове значення; якщо документ з таким ключем уже є - ErrDocumentAlreadyExists
+func (tc *TypedCollection[T]) Insert(value T) (string, error) {
+	doc, err := MarshalDocument(value)
+	if err != nil {
+		return "", err
+	}
+	return tc.coll.Insert(*doc)
+}
+
+// Replace замінює наявне значення; якщо документа з таким ключем немає - ErrDocumentNotFound
+func (tc *TypedCollection[T]) Replace(value T) (string, error) {
+	doc, err := MarshalDocument(value)
+	if err != nil {
+		return "", err
+	}
+	return tc.coll.Replace(*doc)
+}
+
+// Get повертає значення за ключем; для складеного ключа - за значеннями всіх компонентів
+func (tc *TypedCollection[T]) Get(keyValues ...any) (T, error) {
+	var value T
+	doc, err := tc.coll.Get(keyValues...)
+	if err != nil {
+		return value, err
+	}
+	err = UnmarshalDocument(doc, &value)
+	return value, err
+}
+
+// List повертає всі значення колекції; документи, які не вдалося прочитати як T, пропускаються
+func (tc *TypedCollection[T]) List() []T {
+	return tc.fromDocuments(tc.coll.List())
+}
+
+func (tc *TypedCollection[T]) Delete(keyValues ...any) error {
+	return tc.coll.Delete(keyValues...)
+}
+
+// Scan повертає значення в порядку первинного ключа (див. Collection.Scan)
+func (tc *TypedCollection[T]) Scan(r KeyRange) ([]T, error) {
+	docs, err := tc.coll.Scan(r)
+	if err != nil {
+		return nil, err
+	}
+	return tc.fromDocuments(docs), nil
+}
+
+func (tc *TypedCollection[T]) Query(fieldName string, params QueryParams) ([]T, error) {
+	docs, err := tc.coll.Query(fieldName, params)
+	if err != nil {
+		return nil, err
+	}
+	return tc.fromDocuments(docs), nil
+}
+
+func (tc *TypedCollection[T]) fromDocuments(docs []Document) []T {
+	values := make([]T, 0, len(docs))
+	for i := range docs {
+		var value T
+		if err := UnmarshalDocument(&docs[i], &value); err != nil {
+			key := make([]any, len(tc.primaryKeys))
+			for j, f := range tc.primaryKeys {
+				key[j] = docs[i].Fields[f].Value
+			}
+			slog.Warn("TypedCollection: skipping document", slog.Any("key", key), slog.Any("error", err))
+			continue
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// EnsureCollectionFor створює колекцію для типу T (або відкриває наявну)
+// та індекси, описані тегами docstore. Тип повинен мати хоча б одне поле з тегом pk.
+func EnsureCollectionFor[T any](s *Store, name string) (*TypedCollection[T], error) {
+	meta, err := structMetaFor[T]()
+	if err != nil {
+		return nil, err
+	}
+	if len(meta.primaryKeys) == 0 {
+		return nil, fmt.Errorf("%w: add `docstore:\"pk\"` to the key field", ErrPrimaryKeyUndefined)
+	}
+
+	err = s.CreateCollection(name, meta.config())
+	if err != nil && !errors.Is(err, ErrCollectionAlreadyExists) {
+		return nil, err
+	}
+	coll, err := s.GetCollection(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, opts := range meta.indexes {
+		if err := coll.CreateIndexWithOptions(opts); err != nil && !errors.Is(err, ErrIndexExists) {
+			return nil, fmt.Errorf("failed to create index for '%s': %w", name, err)
+		}
+	}
+	return NewTypedCollection[T](coll)
+}
@@ -0,0 +1,219 @@
+package documentstore
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type typedUser struct {
+	ID    string `json:"id" docstore:"pk"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func TestNewTypedCollection(t *testing.T) {
+	type noTag struct {
+		ID string `json:"id"`
+	}
+	type otherKey struct {
+		Email string `json:"email" docstore:"pk"`
+	}
+
+	tests := []struct {
+		name    string
+		newFn   func(*Collection) error
+		wantErr error
+	}{
+		{
+			name: "Tagged primary key",
+			newFn: func(c *Collection) error {
+				_, err := NewTypedCollection[typedUser](c)
+				return err
+			},
+		},
+		{
+			name: "Collection primary key used without tag",
+			newFn: func(c *Collection) error {
+				_, err := NewTypedCollection[noTag](c)
+				return err
+			},
+		},
+		{
+			name: "Tag does not match collection",
+			newFn: func(c *Collection) error {
+				_, err := NewTypedCollection[otherKey](c)
+				return err
+			},
+			wantErr: ErrPrimaryKeyMismatch,
+		},
+		{
+			name: "Not a struct",
+			newFn: func(c *Collection) error {
+				_, err := NewTypedCollection[string](c)
+				return err
+			},
+			wantErr: ErrNotAStruct,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+			if err := tt.newFn(c); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewTypedCollection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTypedCollection(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}, documents: map[string]Document{}}
+	users, err := NewTypedCollection[typedUser](c)
+	if err != nil {
+		t.Fatalf("NewTypedCollection() error = %v", err)
+	}
+	if err := c.CreateIndex("name"); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+
+	alice := typedUser{ID: "1", Name: "Alice", Email: "alice@example.com"}
+	bob := typedUser{ID: "2", Name: "Bob", Email: "bob@example.com"}
+	for _, u := range []typedUser{alice, bob} {
+		if _, err := users.Put(u); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	got, err := users.Get("1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, alice) {
+		t.Errorf("Get() got = %v, want %v", got, alice)
+	}
+
+	queried, err := users.Query("name", QueryParams{Desc: true})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if want := []typedUser{bob, alice}; !reflect.DeepEqual(queried, want) {
+		t.Errorf("Query() got = %v, want %v", queried, want)
+	}
+
+	if err := users.Delete("2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if list := users.List(); !reflect.DeepEqual(list, []typedUser{alice}) {
+		t.Errorf("List() got = %v, want %v", list, []typedUser{alice})
+	}
+	if _, err := users.Get("2"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Get() error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+}
+
+func TestParseDocstoreTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		want    docstoreTag
+		wantErr bool
+	}{
+		{name: "Empty", tag: "", want: docstoreTag{}},
+		{name: "Primary key", tag: "pk", want: docstoreTag{pk: true}},
+		{name: "Unique implies index", tag: "unique", want: docstoreTag{index: true, unique: true}},
+		{name: "Compound member", tag: "compound=by_day,unique", want: docstoreTag{compound: "by_day", unique: true}},
+		{name: "Rename", tag: "name=full_name,index", want: docstoreTag{name: "full_name", index: true}},
+		{name: "Omit", tag: "-", want: docstoreTag{omit: true}},
+		{name: "Omit with options", tag: "-,index", wantErr: true},
+		{name: "Unknown option", tag: "primary", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDocstoreTag(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDocstoreTag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseDocstoreTag() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type taggedVisit struct {
+	ID        string `json:"id" docstore:"pk"`
+	Email     string `json:"email" docstore:"unique"`
+	AccountID string `json:"account_id" docstore:"compound=account_day"`
+	Day       string `json:"day" docstore:"compound=account_day"`
+	Name      string `json:"name" docstore:"name=full_name,index"`
+	Password  string `json:"password" docstore:"-"`
+}
+
+func TestEnsureCollectionFor(t *testing.T) {
+	s := NewStore()
+	visits, err := EnsureCollectionFor[taggedVisit](s, "visits")
+	if err != nil {
+		t.Fatalf("EnsureCollectionFor() error = %v", err)
+	}
+	// Повторний виклик відкриває ту саму колекцію
+	if _, err := EnsureCollectionFor[taggedVisit](s, "visits"); err != nil {
+		t.Fatalf("EnsureCollectionFor() second call error = %v", err)
+	}
+
+	c := visits.Collection()
+	if c.config.PrimaryKey != "id" {
+		t.Errorf("PrimaryKey = %s, want id", c.config.PrimaryKey)
+	}
+	for _, name := range []string{"email", "full_name", "account_day"} {
+		if _, ok := c.indexes[name]; !ok {
+			t.Errorf("index '%s' was not created", name)
+		}
+	}
+	if got := c.indexes["account_day"].Fields; !reflect.DeepEqual(got, []string{"account_id", "day"}) {
+		t.Errorf("compound index fields = %v, want [account_id day]", got)
+	}
+
+	v1 := taggedVisit{ID: "1", Email: "a@x", AccountID: "acc", Day: "2024-01-02", Name: "Alice", Password: "secret"}
+	v2 := taggedVisit{ID: "2", Email: "b@x", AccountID: "acc", Day: "2024-01-01", Name: "Bob"}
+	for _, v := range []taggedVisit{v1, v2} {
+		if _, err := visits.Put(v); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	stored, _ := c.Get("1")
+	if _, ok := stored.Fields["password"]; ok {
+		t.Errorf("omitted field 'password' was stored")
+	}
+	if _, ok := stored.Fields["full_name"]; !ok {
+		t.Errorf("renamed field 'full_name' is missing: %v", stored.Fields)
+	}
+
+	got, err := visits.Get("1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	v1.Password = ""
+	if !reflect.DeepEqual(got, v1) {
+		t.Errorf("Get() got = %+v, want %+v", got, v1)
+	}
+
+	byDay, err := visits.Query("account_day", QueryParams{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(byDay) != 2 || byDay[0].ID != "2" || byDay[1].ID != "1" {
+		t.Errorf("Query() got = %+v, want visits ordered by (account_id, day)", byDay)
+	}
+
+	dup := taggedVisit{ID: "3", Email: "a@x", AccountID: "other", Day: "2024-01-03"}
+	if _, err := visits.Put(dup); !errors.Is(err, ErrUniqueViolation) {
+		t.Errorf("Put() error = %v, wantErr %v", err, ErrUniqueViolation)
+	}
+	// Оновлення того самого документа не порушує унікальність
+	v1.Name = "Alice Smith"
+	if _, err := visits.Put(v1); err != nil {
+		t.Errorf("Put() update error = %v", err)
+	}
+}
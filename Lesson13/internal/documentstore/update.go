@@ -0,0 +1,455 @@
+package documentstore
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidUpdate = errors.New("invalid update")
+
+// Оператори часткового оновлення (синтаксис як у MongoDB)
+const (
+	UpdateSet      = "$set"      // {"$set": {"a.b": value}} - записати значення, створивши проміжні об'єкти
+	UpdateUnset    = "$unset"    // {"$unset": {"a.b": ""}} - видалити поле (елемент масиву стає null)
+	UpdateInc      = "$inc"      // {"$inc": {"visits": 1}} - додати число; відсутнє поле вважається 0
+	UpdatePush     = "$push"     // {"$push": {"tags": "x"}} - додати елемент у кінець масиву
+	UpdatePull     = "$pull"     // {"$pull": {"tags": "x"}} - прибрати всі рівні елементи
+	UpdateAddToSet = "$addToSet" // {"$addToSet": {"tags": "x"}} - додати, якщо такого елемента ще немає
+	UpdateRename   = "$rename"   // {"$rename": {"old": "new"}} - перенести значення в інше поле
+	UpdateMin      = "$min"      // {"$min": {"low": 3}} - записати, якщо значення менше за поточне
+	UpdateMax      = "$max"      // {"$max": {"high": 9}} - записати, якщо значення більше за поточне
+)
+
+// UpdateOps - оператор -> шлях (через крапку; індекси масивів - числами) -> аргумент
+type UpdateOps map[string]map[string]any
+
+// updateOrder - порядок застосування операторів; шляхи не перетинаються, тож порядок
+// впливає лише на повідомлення про помилки
+var updateOrder = []string{UpdateSet, UpdateUnset, UpdateInc, UpdatePush, UpdatePull, UpdateAddToSet, UpdateRename, UpdateMin, UpdateMax}
+
+// Update атомарно застосовує оператори до документа з ключем key (значення ключа
+// або []any зі значеннями компонентів складеного ключа) і повертає оновлений документ.
+// Первинний ключ змінювати не можна; схема та унікальні індекси перевіряються,
+// індекси оновлюються, версія збільшується.
+func (c *Collection) Update(key any, ops UpdateOps) (*Document, error) {
+	if c.config == nil {
+		return nil, errors.New("collection config is not initialized")
+	}
+	if err := ops.check(); err != nil {
+		return nil, err
+	}
+	values, ok := key.([]any)
+	if !ok {
+		values = []any{key}
+	}
+
+	c.mu.Lock()
+	defer c.unlockAndRunHooks()
+
+	internalKey, err := c.lookupKey(values)
+	if err != nil {
+		return nil, err
+	}
+	stored, ok := c.liveLocked(internalKey)
+	if !ok {
+		return nil, ErrDocumentNotFound
+	}
+
+	// Документи в колекції та індексах не змінюються на місці - працюємо з копією
+	doc := Document{Fields: cloneFields(stored.Fields)}
+	if err := ops.apply(doc.Fields); err != nil {
+		return nil, err
+	}
+	if err := c.validate(doc); err != nil {
+		return nil, err
+	}
+	if newKey, err := c.documentKey(doc); err != nil || newKey != internalKey {
+		return nil, fmt.Errorf("%w: primary key cannot be changed", ErrInvalidUpdate)
+	}
+	if doc, err = c.beforePutLocked(ChangeUpdate, internalKey, doc); err != nil {
+		return nil, err
+	}
+	ev, err := c.storeLocked(internalKey, doc)
+	if err != nil {
+		return nil, err
+	}
+	ev.Op = ChangeUpdate
+	c.publishLocked(ev)
+	c.evictLocked(internalKey)
+	doc = *ev.After
+	slog.Debug("Update: document updated", slog.String("key", internalKey), slog.Uint64("version", doc.Version))
+	return &doc, nil
+}
+
+// check перевіряє оператори та те, що шляхи різних операцій не перетинаються
+func (ops UpdateOps) check() error {
+	if len(ops) == 0 {
+		return fmt.Errorf("%w: no operators", ErrInvalidUpdate)
+	}
+	var paths []string
+	for op, args := range ops {
+		if !isUpdateOperator(op) {
+			return fmt.Errorf("%w: unknown operator '%s'", ErrInvalidUpdate, op)
+		}
+		for path, arg := range args {
+			if err := checkUpdatePath(path); err != nil {
+				return err
+			}
+			paths = append(paths, path)
+			if op == UpdateRename {
+				target, ok := arg.(string)
+				if !ok {
+					return fmt.Errorf("%w: $rename target for '%s' must be a string", ErrInvalidUpdate, path)
+				}
+				if err := checkUpdatePath(target); err != nil {
+					return err
+				}
+				paths = append(paths, target)
+			}
+		}
+	}
+	// Сусіди в сортуванні не годяться: "a-b" стоїть між "a" і "a.b",
+	// тож кожен шлях порівнюється з усіма попередніми покомпонентно
+	sort.Strings(paths)
+	for i := 1; i < len(paths); i++ {
+		for j := 0; j < i; j++ {
+			if pathsOverlap(paths[j], paths[i]) {
+				return fmt.Errorf("%w: conflicting paths '%s' and '%s'", ErrInvalidUpdate, paths[j], paths[i])
+			}
+		}
+	}
+	return nil
+}
+
+// pathsOverlap повідомляє, чи один шлях збігається з іншим або є його префіксом
+func pathsOverlap(a, b string) bool {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	n := min(len(pa), len(pb))
+	return slices.Equal(pa[:n], pb[:n])
+}
+
+func isUpdateOperator(op string) bool {
+	for _, known := range updateOrder {
+		if op == known {
+			return true
+		}
+	}
+	return false
+}
+
+func checkUpdatePath(path string) error {
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return fmt.Errorf("%w: invalid path '%s'", ErrInvalidUpdate, path)
+		}
+	}
+	return nil
+}
+
+func (ops UpdateOps) apply(fields map[string]DocumentField) error {
+	for _, op := range updateOrder {
+		args := ops[op]
+		paths := make([]string, 0, len(args))
+		for path := range args {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if err := applyUpdate(fields, op, path, args[path]); err != nil {
+				return fmt.Errorf("%w: %s '%s': %v", ErrInvalidUpdate, op, path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// leafFunc отримує поточне значення (exists == false, якщо його немає) і повертає
+// нове значення; keep == false означає видалити поле
+type leafFunc func(cur DocumentField, exists bool) (next DocumentField, keep bool, err error)
+
+func applyUpdate(fields map[string]DocumentField, op, path string, arg any) error {
+	parts := strings.Split(path, ".")
+	if op == UpdateRename {
+		var moved DocumentField
+		var found bool
+		err := updateAt(fields, parts, false, func(cur DocumentField, exists bool) (DocumentField, bool, error) {
+			moved, found = cur, exists
+			return cur, false, nil
+		})
+		if err != nil || !found {
+			return err
+		}
+		return updateAt(fields, strings.Split(arg.(string), "."), true, func(DocumentField, bool) (DocumentField, bool, error) {
+			return moved, true, nil
+		})
+	}
+
+	var value DocumentField
+	if op != UpdateUnset {
+		var err error
+		if value, err = toDocumentField(arg); err != nil {
+			return err
+		}
+	}
+
+	switch op {
+	case UpdateSet:
+		return updateAt(fields, parts, true, func(DocumentField, bool) (DocumentField, bool, error) {
+			return value, true, nil
+		})
+	case UpdateUnset:
+		return updateAt(fields, parts, false, func(cur DocumentField, exists bool) (DocumentField, bool, error) {
+			return cur, false, nil
+		})
+	case UpdateInc:
+		if !isNumeric(value) {
+			return fmt.Errorf("argument must be a number")
+		}
+		return updateAt(fields, parts, true, func(cur DocumentField, exists bool) (DocumentField, bool, error) {
+			if !exists {
+				return value, true, nil
+			}
+			sum, err := addNumbers(cur, value)
+			return sum, true, err
+		})
+	case UpdatePush, UpdateAddToSet:
+		return updateAt(fields, parts, true, func(cur DocumentField, exists bool) (DocumentField, bool, error) {
+			if !exists {
+				return DocumentField{Type: DocumentFieldTypeArray, Value: []DocumentField{value}}, true, nil
+			}
+			items, ok := cur.Value.([]DocumentField)
+			if cur.Type != DocumentFieldTypeArray || !ok {
+				return cur, true, fmt.Errorf("field is '%s', not an array", cur.Type)
+			}
+			if op == UpdateAddToSet {
+				for _, item := range items {
+					if fieldsEqual(item, value) {
+						return cur, true, nil
+					}
+				}
+			}
+			return DocumentField{Type: DocumentFieldTypeArray, Value: append(items, value)}, true, nil
+		})
+	case UpdatePull:
+		return updateAt(fields, parts, false, func(cur DocumentField, exists bool) (DocumentField, bool, error) {
+			if !exists {
+				return cur, false, nil
+			}
+			items, ok := cur.Value.([]DocumentField)
+			if cur.Type != DocumentFieldTypeArray || !ok {
+				return cur, true, fmt.Errorf("field is '%s', not an array", cur.Type)
+			}
+			kept := make([]DocumentField, 0, len(items))
+			for _, item := range items {
+				if !fieldsEqual(item, value) {
+					kept = append(kept, item)
+				}
+			}
+			return DocumentField{Type: DocumentFieldTypeArray, Value: kept}, true, nil
+		})
+	case UpdateMin, UpdateMax:
+		return updateAt(fields, parts, true, func(cur DocumentField, exists bool) (DocumentField, bool, error) {
+			if !exists {
+				return value, true, nil
+			}
+			if !indexable(cur) || !indexable(value) {
+				return cur, true, fmt.Errorf("cannot compare '%s' with '%s'", cur.Type, value.Type)
+			}
+			c := compareIndexValues(value, cur)
+			if (op == UpdateMin && c < 0) || (op == UpdateMax && c > 0) {
+				return value, true, nil
+			}
+			return cur, true, nil
+		})
+	}
+	return fmt.Errorf("unknown operator")
+}
+
+// updateAt знаходить поле за шляхом і замінює його результатом fn.
+// Якщо create == false, а проміжного поля немає, fn не викликається.
+func updateAt(fields map[string]DocumentField, path []string, create bool, fn leafFunc) error {
+	head := path[0]
+	cur, exists := fields[head]
+	if len(path) == 1 {
+		if !exists && !create {
+			return nil
+		}
+		next, keep, err := fn(cur, exists)
+		if err != nil {
+			return err
+		}
+		if keep {
+			fields[head] = next
+		} else {
+			delete(fields, head)
+		}
+		return nil
+	}
+
+	if !exists {
+		if !create {
+			return nil
+		}
+		cur = DocumentField{Type: DocumentFieldTypeObject, Value: map[string]DocumentField{}}
+	}
+	next, err := descend(cur, head, path[1:], create, fn)
+	if err != nil {
+		return err
+	}
+	fields[head] = next
+	return nil
+}
+
+// descend продовжує шлях усередині об'єкта або масиву parent
+func descend(parent DocumentField, name string, path []string, create bool, fn leafFunc) (DocumentField, error) {
+	switch items := parent.Value.(type) {
+	case map[string]DocumentField:
+		return parent, updateAt(items, path, create, fn)
+	case []DocumentField:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 {
+			return parent, fmt.Errorf("'%s' is an array, '%s' is not an index", name, path[0])
+		}
+		if i >= len(items) {
+			if !create {
+				return parent, nil
+			}
+			return parent, fmt.Errorf("index %d is out of range for '%s'", i, name)
+		}
+		if len(path) == 1 {
+			next, keep, err := fn(items[i], true)
+			if err != nil {
+				return parent, err
+			}
+			if !keep {
+				next = DocumentField{Type: DocumentFieldTypeNull}
+			}
+			items[i] = next
+			return parent, nil
+		}
+		next, err := descend(items[i], path[0], path[1:], create, fn)
+		if err != nil {
+			return parent, err
+		}
+		items[i] = next
+		return parent, nil
+	default:
+		return parent, fmt.Errorf("'%s' is '%s', cannot access '%s'", name, parent.Type, path[0])
+	}
+}
+
+// cloneFields робить глибоку копію вкладених об'єктів і масивів
+func cloneFields(fields map[string]DocumentField) map[string]DocumentField {
+	out := make(map[string]DocumentField, len(fields))
+	for k, v := range fields {
+		out[k] = cloneField(v)
+	}
+	return out
+}
+
+func cloneField(f DocumentField) DocumentField {
+	switch v := f.Value.(type) {
+	case map[string]DocumentField:
+		f.Value = cloneFields(v)
+	case []DocumentField:
+		items := make([]DocumentField, len(v))
+		for i, item := range v {
+			items[i] = cloneField(item)
+		}
+		f.Value = items
+	}
+	return f
+}
+
+func isNumeric(f DocumentField) bool {
+	switch f.Type {
+	case DocumentFieldTypeNumber, DocumentFieldTypeInteger, DocumentFieldTypeDecimal:
+		_, ok := numericRat(f.Value)
+		return ok
+	}
+	return false
+}
+
+// addNumbers складає числа, зберігаючи тип: integer + integer дає integer
+// (decimal при переповненні), decimal - точну суму decimal, інакше - number (float64)
+func addNumbers(a, b DocumentField) (DocumentField, error) {
+	if !isNumeric(a) {
+		return a, fmt.Errorf("field is '%s', not a number", a.Type)
+	}
+	x, y := a.Value, b.Value
+	if xi, ok := x.(int64); ok {
+		if yi, ok := y.(int64); ok {
+			sum := xi + yi
+			if (yi > 0 && sum < xi) || (yi < 0 && sum > xi) {
+				return decimalSum(x, y)
+			}
+			return DocumentField{Type: DocumentFieldTypeInteger, Value: sum}, nil
+		}
+	}
+	if a.Type == DocumentFieldTypeDecimal || b.Type == DocumentFieldTypeDecimal {
+		return decimalSum(x, y)
+	}
+	rx, _ := numericRat(x)
+	ry, _ := numericRat(y)
+	f, _ := new(big.Rat).Add(rx, ry).Float64()
+	if math.IsInf(f, 0) {
+		return a, fmt.Errorf("result overflows float64")
+	}
+	return DocumentField{Type: DocumentFieldTypeNumber, Value: f}, nil
+}
+
+// decimalSum складає через big.Rat; float-доданок береться за десятковим записом,
+// тож 0.1 + 0.2 дає рівно 0.3
+func decimalSum(x, y any) (DocumentField, error) {
+	rx, _ := decimalRat(x)
+	ry, _ := decimalRat(y)
+	n, ok := canonicalNumber(new(big.Rat).Add(rx, ry))
+	if !ok {
+		return DocumentField{}, fmt.Errorf("result has no finite decimal form")
+	}
+	return DocumentField{Type: DocumentFieldTypeDecimal, Value: n}, nil
+}
+
+// fieldsEqual порівнює значення для $pull і $addToSet; числа різних типів рівні,
+// якщо рівні їхні значення
+func fieldsEqual(a, b DocumentField) bool {
+	switch {
+	case indexable(a) && indexable(b):
+		return indexTypeRank(a.Type) == indexTypeRank(b.Type) && compareIndexValues(a, b) == 0
+	case a.Type != b.Type:
+		return false
+	}
+	switch x := a.Value.(type) {
+	case []DocumentField:
+		y, ok := b.Value.([]DocumentField)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !fieldsEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]DocumentField:
+		y, ok := b.Value.(map[string]DocumentField)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !fieldsEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Value == b.Value
+	}
+}
@@ -0,0 +1,182 @@
+package documentstore
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func updateDoc() Document {
+	return txDoc("1", map[string]any{
+		"name":    "Alice",
+		"visits":  1,
+		"score":   2.5,
+		"tags":    []any{"a", "b", "a"},
+		"address": map[string]any{"city": "Kyiv"},
+		"items":   []any{map[string]any{"qty": json.Number("1")}},
+		"price":   json.Number("0.1"),
+	})
+}
+
+func TestCollection_Update(t *testing.T) {
+	tests := []struct {
+		name    string
+		ops     UpdateOps
+		path    []string // шлях до поля, яке перевіряється
+		want    DocumentField
+		missing bool // поле має бути відсутнім
+		wantErr error
+	}{
+		{name: "Set nested creates objects", ops: UpdateOps{UpdateSet: {"profile.bio.text": "hi"}}, path: []string{"profile", "bio", "text"}, want: DocumentField{Type: DocumentFieldTypeString, Value: "hi"}},
+		{name: "Set inside array element", ops: UpdateOps{UpdateSet: {"items.0.qty": 5}}, path: []string{"items", "0", "qty"}, want: DocumentField{Type: DocumentFieldTypeInteger, Value: int64(5)}},
+		{name: "Unset nested", ops: UpdateOps{UpdateUnset: {"address.city": ""}}, path: []string{"address", "city"}, missing: true},
+		{name: "Unset missing is no-op", ops: UpdateOps{UpdateUnset: {"nope.deeper": ""}}, path: []string{"nope"}, missing: true},
+		{name: "Inc integer", ops: UpdateOps{UpdateInc: {"visits": 2}}, path: []string{"visits"}, want: DocumentField{Type: DocumentFieldTypeInteger, Value: int64(3)}},
+		{name: "Inc float", ops: UpdateOps{UpdateInc: {"score": 0.5}}, path: []string{"score"}, want: DocumentField{Type: DocumentFieldTypeNumber, Value: 3.0}},
+		{name: "Inc decimal is exact", ops: UpdateOps{UpdateInc: {"price": json.Number("0.2")}}, path: []string{"price"}, want: DocumentField{Type: DocumentFieldTypeDecimal, Value: json.Number("0.3")}},
+		{name: "Inc decimal by float", ops: UpdateOps{UpdateInc: {"price": 0.2}}, path: []string{"price"}, want: DocumentField{Type: DocumentFieldTypeDecimal, Value: json.Number("0.3")}},
+		{name: "Inc missing starts from zero", ops: UpdateOps{UpdateInc: {"stats.likes": 1}}, path: []string{"stats", "likes"}, want: DocumentField{Type: DocumentFieldTypeInteger, Value: int64(1)}},
+		{name: "Inc json.Number", ops: UpdateOps{UpdateInc: {"items.0.qty": json.Number("4")}}, path: []string{"items", "0", "qty"}, want: DocumentField{Type: DocumentFieldTypeInteger, Value: int64(5)}},
+		{name: "Inc non-number field", ops: UpdateOps{UpdateInc: {"name": 1}}, wantErr: ErrInvalidUpdate},
+		{name: "Push", ops: UpdateOps{UpdatePush: {"tags": "c"}}, path: []string{"tags"}, want: strArray("a", "b", "a", "c")},
+		{name: "Push creates array", ops: UpdateOps{UpdatePush: {"list": "x"}}, path: []string{"list"}, want: strArray("x")},
+		{name: "Push to non-array", ops: UpdateOps{UpdatePush: {"name": "x"}}, wantErr: ErrInvalidUpdate},
+		{name: "Pull removes all equal", ops: UpdateOps{UpdatePull: {"tags": "a"}}, path: []string{"tags"}, want: strArray("b")},
+		{name: "AddToSet existing", ops: UpdateOps{UpdateAddToSet: {"tags": "b"}}, path: []string{"tags"}, want: strArray("a", "b", "a")},
+		{name: "AddToSet new", ops: UpdateOps{UpdateAddToSet: {"tags": "z"}}, path: []string{"tags"}, want: strArray("a", "b", "a", "z")},
+		{name: "Rename to nested", ops: UpdateOps{UpdateRename: {"name": "profile.name"}}, path: []string{"profile", "name"}, want: DocumentField{Type: DocumentFieldTypeString, Value: "Alice"}},
+		{name: "Min lower", ops: UpdateOps{UpdateMin: {"score": 1}}, path: []string{"score"}, want: DocumentField{Type: DocumentFieldTypeInteger, Value: int64(1)}},
+		{name: "Min higher keeps value", ops: UpdateOps{UpdateMin: {"score": 10}}, path: []string{"score"}, want: DocumentField{Type: DocumentFieldTypeNumber, Value: 2.5}},
+		{name: "Max higher", ops: UpdateOps{UpdateMax: {"visits": 7}}, path: []string{"visits"}, want: DocumentField{Type: DocumentFieldTypeInteger, Value: int64(7)}},
+		{name: "Max different types", ops: UpdateOps{UpdateMax: {"visits": "x"}}, path: []string{"visits"}, want: DocumentField{Type: DocumentFieldTypeString, Value: "x"}},
+		{name: "Unknown operator", ops: UpdateOps{"$mul": {"visits": 2}}, wantErr: ErrInvalidUpdate},
+		{name: "Conflicting paths", ops: UpdateOps{UpdateSet: {"address": 1}, UpdateUnset: {"address.city": ""}}, wantErr: ErrInvalidUpdate},
+		{name: "Conflicting paths around sibling", ops: UpdateOps{UpdateSet: {"address": 1, "address-b": 2}, UpdateUnset: {"address.city": ""}}, wantErr: ErrInvalidUpdate},
+		{name: "Similar prefix is not a conflict", ops: UpdateOps{UpdateSet: {"address-b": 2}, UpdateUnset: {"address.city": ""}}, path: []string{"address-b"}, want: DocumentField{Type: DocumentFieldTypeInteger, Value: int64(2)}},
+		{name: "Primary key change", ops: UpdateOps{UpdateSet: {"id": "2"}}, wantErr: ErrInvalidUpdate},
+		{name: "Index out of range", ops: UpdateOps{UpdateSet: {"items.5.qty": 1}}, wantErr: ErrInvalidUpdate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+			if _, _, err := c.Put(updateDoc()); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			got, err := c.Update("1", tt.ops)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				// Невдале оновлення не змінює документ
+				if stored, _ := c.Get("1"); !reflect.DeepEqual(stored.Fields, updateDoc().Fields) {
+					t.Errorf("Update() with error changed document: %v", stored.Fields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if got.Version != 2 {
+				t.Errorf("Update() version = %d, want 2", got.Version)
+			}
+			field, ok := lookupPath(got.Fields, tt.path)
+			if ok == tt.missing {
+				t.Fatalf("field %v present = %v, want %v", tt.path, ok, !tt.missing)
+			}
+			if !tt.missing && !reflect.DeepEqual(field, tt.want) {
+				t.Errorf("Update() got = %v, want %v", field, tt.want)
+			}
+			stored, _ := c.Get("1")
+			if !reflect.DeepEqual(stored.Fields, got.Fields) {
+				t.Errorf("Get() after Update() got = %v, want %v", stored.Fields, got.Fields)
+			}
+		})
+	}
+}
+
+func TestCollection_UpdateMaintainsIndexes(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	if err := c.CreateIndexWithOptions(IndexOptions{Fields: []string{"email"}, Unique: true}); err != nil {
+		t.Fatalf("CreateIndexWithOptions() error = %v", err)
+	}
+	for _, d := range []Document{batchDoc("1", "a@x", 30), batchDoc("2", "b@x", 20)} {
+		if _, _, err := c.Put(d); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	if _, err := c.Update("1", UpdateOps{UpdateSet: {"email": "b@x"}}); !errors.Is(err, ErrUniqueViolation) {
+		t.Errorf("Update() error = %v, wantErr %v", err, ErrUniqueViolation)
+	}
+	if _, err := c.Update([]any{"1"}, UpdateOps{UpdateSet: {"email": "c@x"}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	docs, _ := c.Query("email", QueryParams{})
+	var emails []string
+	for _, d := range docs {
+		emails = append(emails, d.Fields["email"].Value.(string))
+	}
+	if want := []string{"b@x", "c@x"}; !reflect.DeepEqual(emails, want) {
+		t.Errorf("index after Update() = %v, want %v", emails, want)
+	}
+	if _, err := c.Update("404", UpdateOps{UpdateInc: {"age": 1}}); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Update() missing document error = %v, wantErr %v", err, ErrDocumentNotFound)
+	}
+}
+
+func TestCollection_UpdateConcurrentInc(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	if _, _, err := c.Put(txDoc("1", map[string]any{"n": 0})); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := c.Update("1", UpdateOps{UpdateInc: {"n": 1}}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	got, _ := c.Get("1")
+	if n := got.Fields["n"].Value; n != int64(400) {
+		t.Errorf("n = %v, want 400", n)
+	}
+}
+
+func strArray(values ...string) DocumentField {
+	items := make([]DocumentField, len(values))
+	for i, v := range values {
+		items[i] = DocumentField{Type: DocumentFieldTypeString, Value: v}
+	}
+	return DocumentField{Type: DocumentFieldTypeArray, Value: items}
+}
+
+func lookupPath(fields map[string]DocumentField, path []string) (DocumentField, bool) {
+	field, ok := fields[path[0]]
+	for _, part := range path[1:] {
+		if !ok {
+			break
+		}
+		switch v := field.Value.(type) {
+		case map[string]DocumentField:
+			field, ok = v[part]
+		case []DocumentField:
+			var i int
+			if err := json.Unmarshal([]byte(part), &i); err != nil || i >= len(v) {
+				return field, false
+			}
+			field = v[i]
+		default:
+			return field, false
+		}
+	}
+	return field, ok
+}
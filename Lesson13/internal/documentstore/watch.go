@@ -0,0 +1,182 @@
+package documentstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+var (
+	ErrChangeHistoryGone = errors.New("change history no longer contains requested sequence")
+	ErrInvalidWatch      = errors.New("invalid watch filter")
+)
+
+// ChangeOp - тип зміни документа
+type ChangeOp string
+
+const (
+	ChangePut    ChangeOp = "put"    // Put, Insert, Replace, PutMany, запис у транзакції
+	ChangeUpdate ChangeOp = "update" // Update з операторами
+	ChangeDelete ChangeOp = "delete" // Delete, DeleteMany, видалення в транзакції
+	ChangeExpire ChangeOp = "expire" // видалення простроченого документа (DeleteExpired)
+	ChangeEvict  ChangeOp = "evict"  // витіснення з обмеженої колекції (MaxDocuments/MaxBytes)
+)
+
+// OverflowPolicy визначає, що робити, коли буфер підписника заповнено
+type OverflowPolicy string
+
+const (
+	// OverflowClose закриває канал; підписник може поновитися з AfterSeq = Seq останньої отриманої події
+	OverflowClose OverflowPolicy = "close"
+	// OverflowDrop пропускає події, що не вмістилися (для кешів, яким досить "щось змінилося")
+	OverflowDrop OverflowPolicy = "drop"
+)
+
+const (
+	defaultWatchBuffer = 64
+	// changeHistorySize - скільки останніх подій колекція щонайменше пам'ятає для поновлення підписки
+	changeHistorySize = 1024
+)
+
+// ChangeEvent - зміна одного документа. Before - документ до зміни (nil для нового),
+// After - після (nil для видалення). Seq зростає в межах колекції без пропусків.
+type ChangeEvent struct {
+	Seq    uint64
+	Op     ChangeOp
+	Key    string
+	Before *Document
+	After  *Document
+}
+
+// WatchFilter - які події отримувати та як їх доставляти
+type WatchFilter struct {
+	Ops      []ChangeOp // порожньо - усі операції
+	Keys     []string   // внутрішні ключі документів; порожньо - усі документи
+	AfterSeq uint64     // якщо > 0, спершу надсилаються збережені події з Seq > AfterSeq
+	Buffer   int        // розмір буфера каналу, за замовчуванням 64
+	Overflow OverflowPolicy
+}
+
+type watcher struct {
+	ch     chan ChangeEvent
+	done   chan struct{} // закривається разом з ch, щоб зупинити горутину, що чекає на ctx
+	filter WatchFilter
+}
+
+func (f WatchFilter) check() error {
+	switch f.Overflow {
+	case "", OverflowClose, OverflowDrop:
+	default:
+		return fmt.Errorf("%w: unknown overflow policy '%s'", ErrInvalidWatch, f.Overflow)
+	}
+	if f.Buffer < 0 {
+		return fmt.Errorf("%w: negative buffer", ErrInvalidWatch)
+	}
+	return nil
+}
+
+func (f WatchFilter) matches(ev ChangeEvent) bool {
+	return (len(f.Ops) == 0 || slices.Contains(f.Ops, ev.Op)) &&
+		(len(f.Keys) == 0 || slices.Contains(f.Keys, ev.Key))
+}
+
+// Watch підписується на зміни колекції. Канал закривається, коли ctx скасовано,
+// або (за OverflowClose) коли підписник не встигає читати. Події надсилаються
+// без блокування записів, тож повільний підписник не гальмує колекцію.
+// Якщо AfterSeq старіший за історію колекції, повертається ErrChangeHistoryGone.
+func (c *Collection) Watch(ctx context.Context, filter WatchFilter) (<-chan ChangeEvent, error) {
+	if err := filter.check(); err != nil {
+		return nil, err
+	}
+	if filter.Buffer == 0 {
+		filter.Buffer = defaultWatchBuffer
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var replay []ChangeEvent
+	if filter.AfterSeq > 0 && filter.AfterSeq < c.changeSeq {
+		if len(c.changes) == 0 || c.changes[0].Seq > filter.AfterSeq+1 {
+			return nil, fmt.Errorf("%w: requested after %d", ErrChangeHistoryGone, filter.AfterSeq)
+		}
+		for _, ev := range c.changes {
+			if ev.Seq > filter.AfterSeq && filter.matches(ev) {
+				replay = append(replay, ev)
+			}
+		}
+	}
+
+	w := &watcher{ch: make(chan ChangeEvent, filter.Buffer+len(replay)), done: make(chan struct{}), filter: filter}
+	for _, ev := range replay {
+		w.ch <- ev
+	}
+	if c.watchers == nil {
+		c.watchers = make(map[*watcher]struct{})
+	}
+	c.watchers[w] = struct{}{}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.dropWatcherLocked(w)
+		case <-w.done: // підписника вже відключено (OverflowClose)
+		}
+	}()
+	return w.ch, nil
+}
+
+// publishLocked призначає події номер, зберігає її в історії та розсилає підписникам.
+// Викликається під c.mu.Lock() лише для застосованих змін.
+func (c *Collection) publishLocked(ev ChangeEvent) {
+	c.changeSeq++
+	ev.Seq = c.changeSeq
+	// Історія обрізається половинами, щоб не зсувати її при кожному записі
+	if len(c.changes) >= 2*changeHistorySize {
+		c.changes = slices.Clone(c.changes[changeHistorySize:])
+	}
+	c.changes = append(c.changes, ev)
+	if len(c.afterHooks) > 0 {
+		c.pendingAfter = append(c.pendingAfter, ev)
+	}
+
+	for w := range c.watchers {
+		if !w.filter.matches(ev) {
+			continue
+		}
+		select {
+		case w.ch <- ev:
+		default:
+			if w.filter.Overflow == OverflowDrop {
+				continue
+			}
+			slog.Warn("WATCHER OVERFLOW", slog.Uint64("seq", ev.Seq), slog.String("message", "Підписник не встигає читати події, канал закрито"))
+			c.dropWatcherLocked(w)
+		}
+	}
+}
+
+func (c *Collection) dropWatcherLocked(w *watcher) {
+	if _, ok := c.watchers[w]; ok {
+		delete(c.watchers, w)
+		close(w.ch)
+		close(w.done)
+	}
+}
+
+// putEvent і deleteEvent будують подію для запису або видалення документа
+func putEvent(op ChangeOp, key string, before Document, existed bool, after Document) ChangeEvent {
+	ev := ChangeEvent{Op: op, Key: key, After: &after}
+	if existed {
+		ev.Before = &before
+	}
+	return ev
+}
+
+func deleteEvent(op ChangeOp, key string, before Document) ChangeEvent {
+	return ChangeEvent{Op: op, Key: key, Before: &before}
+}
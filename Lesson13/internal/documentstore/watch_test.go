@@ -0,0 +1,231 @@
+package documentstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+type eventSummary struct {
+	Seq    uint64
+	Op     ChangeOp
+	Key    string
+	Before bool
+	After  bool
+}
+
+func summarize(ev ChangeEvent) eventSummary {
+	return eventSummary{Seq: ev.Seq, Op: ev.Op, Key: ev.Key, Before: ev.Before != nil, After: ev.After != nil}
+}
+
+// drain читає всі події, що вже є в буфері каналу
+func drain(ch <-chan ChangeEvent) (events []eventSummary, closed bool) {
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return events, true
+			}
+			events = append(events, summarize(ev))
+		default:
+			return events, false
+		}
+	}
+}
+
+func TestCollection_Watch(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	all, err := c.Watch(ctx, WatchFilter{})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	deletes, err := c.Watch(ctx, WatchFilter{Ops: []ChangeOp{ChangeDelete}})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	if _, _, err := c.Put(txDoc("1", map[string]any{"n": 1})); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := c.Update("1", UpdateOps{UpdateInc: {"n": 1}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := c.PutMany([]Document{txDoc("2", nil)}, BatchAllOrNothing); err != nil {
+		t.Fatalf("PutMany() error = %v", err)
+	}
+	if err := c.Delete("1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	// Невдалі операції подій не створюють
+	c.Delete("404")
+	c.Insert(txDoc("2", nil))
+
+	want := []eventSummary{
+		{Seq: 1, Op: ChangePut, Key: "1", After: true},
+		{Seq: 2, Op: ChangeUpdate, Key: "1", Before: true, After: true},
+		{Seq: 3, Op: ChangePut, Key: "2", After: true},
+		{Seq: 4, Op: ChangeDelete, Key: "1", Before: true},
+	}
+	if got, _ := drain(all); !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch() events = %v, want %v", got, want)
+	}
+	if got, _ := drain(deletes); !reflect.DeepEqual(got, want[3:]) {
+		t.Errorf("Watch(delete) events = %v, want %v", got, want[3:])
+	}
+
+	cancel()
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-all:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("Watch() channel not closed after cancel")
+		}
+	}
+}
+
+func TestCollection_WatchOverflow(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     OverflowPolicy
+		wantEvents int
+		wantClosed bool
+	}{
+		{name: "Close", policy: OverflowClose, wantEvents: 2, wantClosed: true},
+		{name: "Drop", policy: OverflowDrop, wantEvents: 2, wantClosed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+			ch, err := c.Watch(context.Background(), WatchFilter{Buffer: 2, Overflow: tt.policy})
+			if err != nil {
+				t.Fatalf("Watch() error = %v", err)
+			}
+			for _, id := range []string{"1", "2", "3", "4"} {
+				if _, _, err := c.Put(txDoc(id, nil)); err != nil {
+					t.Fatalf("Put() error = %v", err)
+				}
+			}
+			got, closed := drain(ch)
+			if len(got) != tt.wantEvents || closed != tt.wantClosed {
+				t.Errorf("events = %d, closed = %v, want %d, %v", len(got), closed, tt.wantEvents, tt.wantClosed)
+			}
+		})
+	}
+}
+
+// Підписник, відключений через OverflowClose, не лишає горутину, що чекає на ctx
+func TestCollection_WatchOverflowReleasesGoroutine(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := c.Watch(ctx, WatchFilter{Buffer: 1, Overflow: OverflowClose})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if _, _, err := c.Put(txDoc(id, nil)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	if _, closed := drain(ch); !closed {
+		t.Fatalf("Watch() channel not closed on overflow")
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines = %d, want %d after overflow", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCollection_WatchResume(t *testing.T) {
+	c := &Collection{config: &CollectionConfig{PrimaryKey: "id"}}
+	for _, id := range []string{"1", "2", "3"} {
+		if _, _, err := c.Put(txDoc(id, nil)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	ch, err := c.Watch(context.Background(), WatchFilter{AfterSeq: 1})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if _, _, err := c.Put(txDoc("4", nil)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, _ := drain(ch)
+	var seqs []uint64
+	for _, ev := range got {
+		seqs = append(seqs, ev.Seq)
+	}
+	if want := []uint64{2, 3, 4}; !reflect.DeepEqual(seqs, want) {
+		t.Errorf("resumed seqs = %v, want %v", seqs, want)
+	}
+
+	// Після дампу номери продовжуються, але історії вже немає
+	s := NewStore()
+	s.collections["c"] = c
+	dump, err := s.Dump()
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	restored, err := NewStoreFromDump(dump)
+	if err != nil {
+		t.Fatalf("NewStoreFromDump() error = %v", err)
+	}
+	rc, _ := restored.GetCollection("c")
+	if _, err := rc.Watch(context.Background(), WatchFilter{AfterSeq: 2}); !errors.Is(err, ErrChangeHistoryGone) {
+		t.Errorf("Watch() after restore error = %v, wantErr %v", err, ErrChangeHistoryGone)
+	}
+	live, err := rc.Watch(context.Background(), WatchFilter{AfterSeq: 4})
+	if err != nil {
+		t.Fatalf("Watch() from current seq error = %v", err)
+	}
+	if _, _, err := rc.Put(txDoc("5", nil)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got, _ := drain(live); len(got) != 1 || got[0].Seq != 5 {
+		t.Errorf("events after restore = %v, want seq 5", got)
+	}
+}
+
+func TestTx_WatchPublishesOnlyCommitted(t *testing.T) {
+	s := newTxStore(t, "users")
+	users, _ := s.GetCollection("users")
+	if err := users.CreateIndexWithOptions(IndexOptions{Fields: []string{"email"}, Unique: true}); err != nil {
+		t.Fatalf("CreateIndexWithOptions() error = %v", err)
+	}
+	ch, err := users.Watch(context.Background(), WatchFilter{})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	failed := s.Begin()
+	failed.Put("users", txDoc("1", map[string]any{"email": "a@x"}))
+	failed.Put("users", txDoc("2", map[string]any{"email": "a@x"}))
+	if err := failed.Commit(); !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("Commit() error = %v, wantErr %v", err, ErrUniqueViolation)
+	}
+	if got, _ := drain(ch); len(got) != 0 {
+		t.Errorf("events after rolled back Commit() = %v, want none", got)
+	}
+
+	ok := s.Begin()
+	ok.Put("users", txDoc("1", map[string]any{"email": "a@x"}))
+	if err := ok.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got, _ := drain(ch); len(got) != 1 || got[0].Seq != 1 {
+		t.Errorf("events after Commit() = %v, want one with seq 1", got)
+	}
+}
@@ -18,6 +18,7 @@ type Response struct {
 // Error - структура для повідомлень про помилки
 type Error struct {
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty"` // машинозчитуваний код, наприклад ErrorCodeVersionConflict
 }
 
 // CollectionConfigPayload - структура для payload команди create_collection
@@ -31,16 +32,44 @@ type CollectionNamePayload struct {
 	Name string `json:"name"`
 }
 
-// PutDocumentPayload - структура для payload команди put_document
+// PutDocumentPayload - структура для payload команд put_document, insert_document, replace_document
+// (Version підтримує лише put_document; insert_document і replace_document його відхиляють)
 type PutDocumentPayload struct {
 	Collection string                 `json:"collection"`
 	Document   map[string]interface{} `json:"document"`
+	Version    *uint64                `json:"version,omitempty"` // якщо задано, документ записується лише за цієї версії (0 - лише новий)
 }
 
 // GetDeleteDocumentPayload - структура для payload команд get_document, delete_document
 type GetDeleteDocumentPayload struct {
-	Collection string `json:"collection"`
-	Key        string `json:"key"`
+	Collection string  `json:"collection"`
+	Key        string  `json:"key"`
+	Keys       []any   `json:"keys,omitempty"`    // значення компонентів складеного ключа; замість Key
+	Version    *uint64 `json:"version,omitempty"` // для delete_document: видалити лише за цієї версії
+}
+
+// KeyValues повертає ключ документа у вигляді, який приймають Collection.Get/Delete
+func (p GetDeleteDocumentPayload) KeyValues() []any {
+	if len(p.Keys) > 0 {
+		return p.Keys
+	}
+	return []any{p.Key}
+}
+
+// UpdateDocumentPayload - структура для payload команди update_document
+type UpdateDocumentPayload struct {
+	Collection string                    `json:"collection"`
+	Key        string                    `json:"key"`
+	Keys       []any                     `json:"keys,omitempty"` // значення компонентів складеного ключа; замість Key
+	Update     map[string]map[string]any `json:"update"`         // оператори: {"$set": {"a.b": 1}, "$inc": {"n": 1}}
+}
+
+// KeyValues повертає ключ документа у вигляді, який приймає Collection.Update
+func (p UpdateDocumentPayload) KeyValues() []any {
+	if len(p.Keys) > 0 {
+		return p.Keys
+	}
+	return []any{p.Key}
 }
 
 // ListCollectionsResult - структура для результату команди list_collections
@@ -51,6 +80,13 @@ type ListCollectionsResult struct {
 // GetDocumentResult - структура для результату команди get_document
 type GetDocumentResult struct {
 	Document map[string]interface{} `json:"document"`
+	Version  uint64                 `json:"version"`
+}
+
+// UpdateDocumentResult - структура для результату команди update_document
+type UpdateDocumentResult struct {
+	Document map[string]interface{} `json:"document"`
+	Version  uint64                 `json:"version"`
 }
 
 // ListDocumentsResult - структура для результату команди list_documents
@@ -58,6 +94,13 @@ type ListDocumentsResult struct {
 	Documents []map[string]interface{} `json:"documents"`
 }
 
+// PutDocumentResult - структура для результату команд put_document, insert_document, replace_document
+type PutDocumentResult struct {
+	Key     string `json:"key"`
+	Version uint64 `json:"version,omitempty"` // нова версія документа (для put_document)
+	Message string `json:"message"`
+}
+
 // GenericResult - структура для простих результатів (ok/error повідомлення)
 type GenericResult struct {
 	Message string `json:"message"`
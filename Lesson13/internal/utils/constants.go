@@ -3,3 +3,11 @@ package utils
 const (
 	Delimiter = "\n"
 )
+
+// Коди помилок у Response.Error.Code
+const (
+	ErrorCodeVersionConflict = "version_conflict"
+	ErrorCodeAlreadyExists   = "already_exists"  // insert_document: документ з таким ключем уже є
+	ErrorCodeNotFound        = "not_found"       // документа з таким ключем немає
+	ErrorCodeInvalidPayload  = "invalid_payload" // payload містить поле, яке команда не підтримує
+)
@@ -3,6 +3,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
@@ -103,6 +104,58 @@ func handlePutDocument(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, StandardResponse{Ok: true})
 }
 
+func handleInsertDocument(w http.ResponseWriter, r *http.Request) {
+	var req PutDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONResponse(w, http.StatusBadRequest, StandardResponse{Ok: false, Error: "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.CollectionName == "" {
+		writeJSONResponse(w, http.StatusBadRequest, StandardResponse{Ok: false, Error: "collection_name is required"})
+		return
+	}
+	if req.Document == nil {
+		writeJSONResponse(w, http.StatusBadRequest, StandardResponse{Ok: false, Error: "document is required"})
+		return
+	}
+
+	if err := store.InsertMongoDocument(r.Context(), req.CollectionName, req.Document); err != nil {
+		if errors.Is(err, ErrDocumentAlreadyExists) {
+			writeJSONResponse(w, http.StatusConflict, StandardResponse{Ok: false, Error: err.Error()})
+		} else {
+			writeJSONResponse(w, http.StatusInternalServerError, StandardResponse{Ok: false, Error: err.Error()})
+		}
+		return
+	}
+	writeJSONResponse(w, http.StatusCreated, StandardResponse{Ok: true}) // 201 Created
+}
+
+func handleReplaceDocument(w http.ResponseWriter, r *http.Request) {
+	var req PutDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONResponse(w, http.StatusBadRequest, StandardResponse{Ok: false, Error: "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.CollectionName == "" {
+		writeJSONResponse(w, http.StatusBadRequest, StandardResponse{Ok: false, Error: "collection_name is required"})
+		return
+	}
+	if req.Document == nil || req.Document["_id"] == nil {
+		writeJSONResponse(w, http.StatusBadRequest, StandardResponse{Ok: false, Error: "document with _id is required"})
+		return
+	}
+
+	if err := store.ReplaceMongoDocument(r.Context(), req.CollectionName, req.Document); err != nil {
+		if errors.Is(err, ErrDocumentNotFound) {
+			writeJSONResponse(w, http.StatusNotFound, StandardResponse{Ok: false, Error: err.Error()})
+		} else {
+			writeJSONResponse(w, http.StatusInternalServerError, StandardResponse{Ok: false, Error: err.Error()})
+		}
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, StandardResponse{Ok: true})
+}
+
 func handleGetDocument(w http.ResponseWriter, r *http.Request) {
 	var req GetDocumentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
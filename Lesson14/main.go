@@ -74,6 +74,8 @@ func main() {
 	http.HandleFunc("/delete_collection", makePostHandler(handleDeleteCollection))
 
 	http.HandleFunc("/put_document", makePostHandler(handlePutDocument))
+	http.HandleFunc("/insert_document", makePostHandler(handleInsertDocument))
+	http.HandleFunc("/replace_document", makePostHandler(handleReplaceDocument))
 	http.HandleFunc("/get_document", makePostHandler(handleGetDocument))
 	http.HandleFunc("/list_documents", makePostHandler(handleListDocuments))
 	http.HandleFunc("/delete_document", makePostHandler(handleDeleteDocument))
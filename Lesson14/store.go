@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -12,6 +13,11 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	ErrDocumentAlreadyExists = errors.New("document already exists")
+	ErrDocumentNotFound      = errors.New("document not found")
+)
+
 type MongoStore struct {
 	client *mongo.Client
 	dbName string
@@ -61,23 +67,9 @@ func (s *MongoStore) DeleteMongoCollection(ctx context.Context, collName string)
 
 func (s *MongoStore) PutMongoDocument(ctx context.Context, collName string, document bson.M) error {
 	collection := s.client.Database(s.dbName).Collection(collName)
-	id, idExists := document["_id"]
+	filter, idExists := documentIDFilter(document)
 
 	if idExists {
-		var filter bson.M
-		if idStr, ok := id.(string); ok {
-			objID, err := primitive.ObjectIDFromHex(idStr)
-			if err == nil {
-				filter = bson.M{"_id": objID}
-				document["_id"] = objID // Оновлюємо документ для запису з правильним типом _id
-			} else {
-				filter = bson.M{"_id": idStr} // Використовуємо рядок як є
-			}
-		} else {
-			// Якщо _id не рядок (наприклад, вже ObjectID, int), використовуємо як є
-			filter = bson.M{"_id": id}
-		}
-
 		opts := options.Replace().SetUpsert(true)
 		_, err := collection.ReplaceOne(ctx, filter, document, opts)
 		if err != nil {
@@ -92,6 +84,59 @@ func (s *MongoStore) PutMongoDocument(ctx context.Context, collName string, docu
 	return nil
 }
 
+// InsertMongoDocument лише створює документ: якщо документ з таким _id уже є,
+// повертається ErrDocumentAlreadyExists. Без _id MongoDB згенерує його сама.
+func (s *MongoStore) InsertMongoDocument(ctx context.Context, collName string, document bson.M) error {
+	collection := s.client.Database(s.dbName).Collection(collName)
+	documentIDFilter(document) // приводимо _id до ObjectID, як у PutMongoDocument
+
+	if _, err := collection.InsertOne(ctx, document); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: _id '%v' in collection '%s'", ErrDocumentAlreadyExists, document["_id"], collName)
+		}
+		return fmt.Errorf("failed to insert document: %w", err)
+	}
+	return nil
+}
+
+// ReplaceMongoDocument лише замінює наявний документ (без upsert):
+// якщо документа з таким _id немає, повертається ErrDocumentNotFound.
+func (s *MongoStore) ReplaceMongoDocument(ctx context.Context, collName string, document bson.M) error {
+	collection := s.client.Database(s.dbName).Collection(collName)
+	filter, idExists := documentIDFilter(document)
+	if !idExists {
+		return fmt.Errorf("document _id is required for replace")
+	}
+
+	result, err := collection.ReplaceOne(ctx, filter, document)
+	if err != nil {
+		return fmt.Errorf("failed to replace document: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: _id '%v' in collection '%s'", ErrDocumentNotFound, document["_id"], collName)
+	}
+	return nil
+}
+
+// documentIDFilter будує фільтр за _id документа. Рядковий _id, що є hex ObjectID,
+// перетворюється на ObjectID і в самому документі, щоб запис мав правильний тип _id.
+func documentIDFilter(document bson.M) (bson.M, bool) {
+	id, idExists := document["_id"]
+	if !idExists {
+		return nil, false
+	}
+	if idStr, ok := id.(string); ok {
+		objID, err := primitive.ObjectIDFromHex(idStr)
+		if err == nil {
+			document["_id"] = objID
+			return bson.M{"_id": objID}, true
+		}
+		return bson.M{"_id": idStr}, true // Використовуємо рядок як є
+	}
+	// Якщо _id не рядок (наприклад, вже ObjectID, int), використовуємо як є
+	return bson.M{"_id": id}, true
+}
+
 func getIDFilter(docID string) bson.M {
 	objID, err := primitive.ObjectIDFromHex(docID)
 	if err == nil {
@@ -0,0 +1,132 @@
+package lru
+
+// arcCache - Adaptive Replacement Cache (Megiddo, Modha). Резидентні ключі
+// розділені між recent (бачені один раз) і frequent (бачені двічі й більше);
+// recentGhost і frequentGhost пам'ятають нещодавно витіснені з них ключі.
+// Запит ключа з ghost зсуває ціль target - бажаний розмір recent - у бік
+// списку, якому бракувало місця. Не потокобезпечний.
+type arcCache[K comparable, V any] struct {
+	capacity      int
+	target        int
+	values        map[K]V
+	recent        *keyList[K]
+	frequent      *keyList[K]
+	recentGhost   *keyList[K]
+	frequentGhost *keyList[K]
+}
+
+// NewARC створює ARC-кеш місткістю capacity; capacity <= 0 - без обмеження
+func NewARC[K comparable, V any](capacity int) Cache[K, V] {
+	return &arcCache[K, V]{
+		capacity:      capacity,
+		values:        make(map[K]V),
+		recent:        newKeyList[K](),
+		frequent:      newKeyList[K](),
+		recentGhost:   newKeyList[K](),
+		frequentGhost: newKeyList[K](),
+	}
+}
+
+func (c *arcCache[K, V]) Put(key K, value V) {
+	if _, exists := c.values[key]; exists {
+		c.values[key] = value
+		c.promote(key)
+		return
+	}
+	if c.capacity <= 0 {
+		c.values[key] = value
+		c.recent.PushFront(key)
+		return
+	}
+
+	switch {
+	case c.recentGhost.Contains(key):
+		c.target = min(c.capacity, c.target+max(1, c.frequentGhost.Len()/c.recentGhost.Len()))
+		c.replace(false)
+		c.recentGhost.Remove(key)
+		c.frequent.PushFront(key)
+	case c.frequentGhost.Contains(key):
+		c.target = max(0, c.target-max(1, c.recentGhost.Len()/c.frequentGhost.Len()))
+		c.replace(true)
+		c.frequentGhost.Remove(key)
+		c.frequent.PushFront(key)
+	default:
+		if c.recent.Len()+c.recentGhost.Len() >= c.capacity {
+			if c.recent.Len() < c.capacity {
+				c.recentGhost.RemoveBack()
+				c.replace(false)
+			} else if old, ok := c.recent.RemoveBack(); ok {
+				delete(c.values, old)
+			}
+		} else if total := c.recent.Len() + c.frequent.Len() + c.recentGhost.Len() + c.frequentGhost.Len(); total >= c.capacity {
+			if total >= 2*c.capacity {
+				c.frequentGhost.RemoveBack()
+			}
+			c.replace(false)
+		}
+		c.recent.PushFront(key)
+	}
+	c.values[key] = value
+}
+
+func (c *arcCache[K, V]) Get(key K) (V, bool) {
+	value, exists := c.values[key]
+	if exists {
+		c.promote(key)
+	}
+	return value, exists
+}
+
+func (c *arcCache[K, V]) Peek(key K) (V, bool) {
+	value, exists := c.values[key]
+	return value, exists
+}
+
+func (c *arcCache[K, V]) Delete(key K) bool {
+	if _, exists := c.values[key]; !exists {
+		return false
+	}
+	delete(c.values, key)
+	if !c.recent.Remove(key) {
+		c.frequent.Remove(key)
+	}
+	return true
+}
+
+func (c *arcCache[K, V]) Len() int {
+	return len(c.values)
+}
+
+// promote робить резидентний ключ найсвіжішим у frequent
+func (c *arcCache[K, V]) promote(key K) {
+	if c.recent.Remove(key) {
+		c.frequent.PushFront(key)
+		return
+	}
+	c.frequent.MoveToFront(key)
+}
+
+// replace витісняє один резидентний ключ у відповідний ghost, якщо кеш заповнений:
+// з recent, коли він більший за target, інакше з frequent.
+// inFrequentGhost - новий ключ знайдено у frequentGhost.
+func (c *arcCache[K, V]) replace(inFrequentGhost bool) {
+	if len(c.values) < c.capacity {
+		return
+	}
+	fromRecent := c.recent.Len() > 0 &&
+		(c.recent.Len() > c.target || (inFrequentGhost && c.recent.Len() == c.target))
+	if !fromRecent && c.frequent.Len() == 0 {
+		fromRecent = true
+	}
+	if fromRecent {
+		if key, ok := c.recent.RemoveBack(); ok {
+			delete(c.values, key)
+			c.recentGhost.PushFront(key)
+		}
+		return
+	}
+	if key, ok := c.frequent.RemoveBack(); ok {
+		delete(c.values, key)
+		c.frequentGhost.PushFront(key)
+	}
+}
@@ -2,54 +2,263 @@ package lru
 
 import (
 	"container/list"
+	"time"
 )
 
-type LRUCache interface {
-	Put(key, value string)
-	Get(key string) (string, bool)
+// Cache - спільна поверхня кешів з різними політиками витіснення (LRU, LFU, 2Q, ARC)
+type Cache[K comparable, V any] interface {
+	Put(key K, value V)
+	Get(key K) (V, bool)
+	// Peek повертає значення, не змінюючи стан політики витіснення
+	Peek(key K) (V, bool)
+	// Delete видаляє ключ і повідомляє, чи він був у кеші
+	Delete(key K) bool
+	// Len повертає кількість записів у кеші
+	Len() int
 }
 
-type lruCacheImpl struct {
+// LRUCache - Cache з політикою LRU, терміном дії записів і лічильниками.
+// Put використовує TTL за замовчуванням (Options.TTL), а Len враховує й
+// прострочені записи, які ще не видалено.
+type LRUCache[K comparable, V any] interface {
+	Cache[K, V]
+	// PutWithTTL додає значення, що застаріває через ttl; ttl <= 0 - без терміну дії
+	PutWithTTL(key K, value V, ttl time.Duration)
+	// Keys повертає ключі від нещодавно використаного до найдавнішого
+	Keys() []K
+	// Purge очищає кеш
+	Purge()
+	// DeleteExpired видаляє прострочені записи і повертає їх кількість
+	DeleteExpired() int
+	// Stats повертає знімок лічильників кешу
+	Stats() Stats
+	// Resize змінює місткість; при зменшенні витісняє найдавніше використані
+	// записи і повертає їх кількість. capacity <= 0 - кеш без обмеження розміру.
+	Resize(capacity int) int
+}
+
+// Stats - лічильники кешу з моменту створення
+type Stats struct {
+	Hits        uint64 // Get знайшов живий запис
+	Misses      uint64 // Get не знайшов запис або він прострочений
+	Insertions  uint64 // додано новий ключ (заміна значення не рахується)
+	Evictions   uint64 // витіснено через місткість, зокрема при Resize
+	Expirations uint64 // видалено прострочений запис
+}
+
+// EvictReason - причина, з якої запис залишив кеш
+type EvictReason string
+
+const (
+	EvictCapacity EvictReason = "capacity" // витіснено найдавніше використаний запис
+	EvictExpired  EvictReason = "expired"  // минув термін дії
+	EvictDeleted  EvictReason = "deleted"  // Delete або Purge
+	EvictReplaced EvictReason = "replaced" // Put з тим самим ключем
+)
+
+type Options[K comparable, V any] struct {
+	// TTL - термін дії записів, доданих через Put; 0 - без терміну дії
+	TTL time.Duration
+	// OnEvict викликається для кожного запису, що залишив кеш. У потокобезпечних
+	// кешах виконується під блокуванням, тож не повинен звертатися до кешу.
+	OnEvict func(key K, value V, reason EvictReason)
+}
+
+// timeNow - годинник для перевірки терміну дії; тести підміняють його
+var timeNow = time.Now
+
+// lruCacheImpl не потокобезпечний: навіть Get змінює порядок у списку.
+// Для конкурентного доступу - NewSynced або NewSharded.
+type lruCacheImpl[K comparable, V any] struct {
 	capacity int
-	items    map[string]*list.Element
+	items    map[K]*list.Element
 	order    *list.List
+	options  Options[K, V]
+	stats    Stats
+}
+
+// NewLruCache створює кеш рядків місткістю capacity
+func NewLruCache(capacity int) LRUCache[string, string] {
+	return New[string, string](capacity)
 }
 
-func NewLruCache(capacity int) LRUCache {
-	return &lruCacheImpl{
+// New створює кеш місткістю capacity для довільних ключів і значень;
+// capacity <= 0 - кеш без обмеження розміру
+func New[K comparable, V any](capacity int) LRUCache[K, V] {
+	return NewWithOptions(capacity, Options[K, V]{})
+}
+
+// NewWithOptions створює кеш місткістю capacity з терміном дії та OnEvict
+func NewWithOptions[K comparable, V any](capacity int, options Options[K, V]) LRUCache[K, V] {
+	return &lruCacheImpl[K, V]{
 		capacity: capacity,
-		items:    make(map[string]*list.Element),
+		items:    make(map[K]*list.Element),
 		order:    list.New(),
+		options:  options,
 	}
 }
 
-func (c *lruCacheImpl) Put(key, value string) {
+func (c *lruCacheImpl[K, V]) Put(key K, value V) {
+	c.PutWithTTL(key, value, c.options.TTL)
+}
+
+func (c *lruCacheImpl[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = timeNow().Add(ttl)
+	}
 	if el, exists := c.items[key]; exists {
-		el.Value.(*entry).value = value
+		e := el.Value.(*entry[K, V])
+		old := e.value
+		reason := EvictReplaced
+		if e.expired(timeNow()) {
+			reason = EvictExpired
+		}
+		e.value, e.expiresAt = value, expiresAt
 		c.order.MoveToFront(el)
+		c.evicted(key, old, reason)
 		return
 	}
-	if c.order.Len() == c.capacity {
-		oldest := c.order.Back()
-		if oldest != nil {
-			c.order.Remove(oldest)
-			delete(c.items, oldest.Value.(*entry).key)
-		}
-	}
-	newEntry := &entry{key: key, value: value}
+	c.shrink(c.capacity - 1)
+	newEntry := &entry[K, V]{key: key, value: value, expiresAt: expiresAt}
 	element := c.order.PushFront(newEntry)
 	c.items[key] = element
+	c.stats.Insertions++
 }
 
-func (c *lruCacheImpl) Get(key string) (string, bool) {
-	if el, exists := c.items[key]; exists {
+func (c *lruCacheImpl[K, V]) Get(key K) (V, bool) {
+	if el, exists := c.live(key); exists {
 		c.order.MoveToFront(el)
-		return el.Value.(*entry).value, true
+		c.stats.Hits++
+		return el.Value.(*entry[K, V]).value, true
+	}
+	c.stats.Misses++
+	var zero V
+	return zero, false
+}
+
+func (c *lruCacheImpl[K, V]) Peek(key K) (V, bool) {
+	if el, exists := c.live(key); exists {
+		return el.Value.(*entry[K, V]).value, true
 	}
-	return "", false
+	var zero V
+	return zero, false
+}
+
+func (c *lruCacheImpl[K, V]) Delete(key K) bool {
+	el, exists := c.live(key)
+	if !exists {
+		return false
+	}
+	c.removeElement(el, EvictDeleted)
+	return true
+}
+
+func (c *lruCacheImpl[K, V]) Len() int {
+	return c.order.Len()
+}
+
+func (c *lruCacheImpl[K, V]) Keys() []K {
+	now := timeNow()
+	keys := make([]K, 0, c.order.Len())
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		if e := el.Value.(*entry[K, V]); !e.expired(now) {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+func (c *lruCacheImpl[K, V]) Purge() {
+	now := timeNow()
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*entry[K, V])
+		reason := EvictDeleted
+		if e.expired(now) {
+			reason = EvictExpired
+		}
+		c.evicted(e.key, e.value, reason)
+	}
+	c.items = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+func (c *lruCacheImpl[K, V]) Stats() Stats {
+	return c.stats
+}
+
+func (c *lruCacheImpl[K, V]) Resize(capacity int) int {
+	c.capacity = capacity
+	return c.shrink(capacity)
+}
+
+// shrink витісняє найдавніше використані записи, поки їх більше за limit;
+// для кешу без обмеження нічого не робить
+func (c *lruCacheImpl[K, V]) shrink(limit int) int {
+	if c.capacity <= 0 {
+		return 0
+	}
+	evicted := 0
+	for c.order.Len() > limit {
+		c.removeElement(c.order.Back(), EvictCapacity)
+		evicted++
+	}
+	return evicted
+}
+
+func (c *lruCacheImpl[K, V]) DeleteExpired() int {
+	now := timeNow()
+	removed := 0
+	for el := c.order.Back(); el != nil; {
+		prev := el.Prev()
+		if el.Value.(*entry[K, V]).expired(now) {
+			c.removeElement(el, EvictExpired)
+			removed++
+		}
+		el = prev
+	}
+	return removed
+}
+
+// live повертає елемент, якщо він є і ще не прострочений; прострочений
+// елемент видаляється одразу
+func (c *lruCacheImpl[K, V]) live(key K) (*list.Element, bool) {
+	el, exists := c.items[key]
+	if !exists {
+		return nil, false
+	}
+	if el.Value.(*entry[K, V]).expired(timeNow()) {
+		c.removeElement(el, EvictExpired)
+		return nil, false
+	}
+	return el, true
+}
+
+func (c *lruCacheImpl[K, V]) removeElement(el *list.Element, reason EvictReason) {
+	e := el.Value.(*entry[K, V])
+	c.order.Remove(el)
+	delete(c.items, e.key)
+	c.evicted(e.key, e.value, reason)
+}
+
+func (c *lruCacheImpl[K, V]) evicted(key K, value V, reason EvictReason) {
+	switch reason {
+	case EvictCapacity:
+		c.stats.Evictions++
+	case EvictExpired:
+		c.stats.Expirations++
+	}
+	if c.options.OnEvict != nil {
+		c.options.OnEvict(key, value, reason)
+	}
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time // нульове значення - без терміну дії
 }
 
-type entry struct {
-	key   string
-	value string
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
 }
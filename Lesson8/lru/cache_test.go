@@ -2,7 +2,9 @@ package lru
 
 import (
 	"container/list"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewLruCache(t *testing.T) {
@@ -12,12 +14,12 @@ func TestNewLruCache(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want LRUCache
+		want LRUCache[string, string]
 	}{
 		{
 			name: "Create cache with capacity 2",
 			args: args{capacity: 2},
-			want: &lruCacheImpl{
+			want: &lruCacheImpl[string, string]{
 				capacity: 2,
 				items:    map[string]*list.Element{},
 				order:    list.New(),
@@ -30,12 +32,12 @@ func TestNewLruCache(t *testing.T) {
 			if got == nil {
 				t.Fatalf("NewLruCache() returned nil")
 			}
-			cImpl, ok := got.(*lruCacheImpl)
+			cImpl, ok := got.(*lruCacheImpl[string, string])
 			if !ok {
-				t.Fatalf("Expected *lruCacheImpl, got %T", got)
+				t.Fatalf("Expected *lruCacheImpl[string, string], got %T", got)
 			}
-			if cImpl.capacity != tt.want.(*lruCacheImpl).capacity {
-				t.Errorf("Expected capacity %v, got %v", tt.want.(*lruCacheImpl).capacity, cImpl.capacity)
+			if cImpl.capacity != tt.want.(*lruCacheImpl[string, string]).capacity {
+				t.Errorf("Expected capacity %v, got %v", tt.want.(*lruCacheImpl[string, string]).capacity, cImpl.capacity)
 			}
 		})
 	}
@@ -112,3 +114,280 @@ func Test_lruCacheImpl_Put(t *testing.T) {
 		}
 	})
 }
+
+func TestNew_Generic(t *testing.T) {
+	cache := New[int, []string](2)
+	cache.Put(1, []string{"a"})
+	cache.Put(2, []string{"b", "c"})
+
+	got, ok := cache.Get(2)
+	if !ok || !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("Get(2) = (%v, %v), want ([b c], true)", got, ok)
+	}
+	if got, ok := cache.Get(3); ok || got != nil {
+		t.Errorf("Get(3) = (%v, %v), want (nil, false)", got, ok)
+	}
+}
+
+func Test_lruCacheImpl_Peek(t *testing.T) {
+	cache := NewLruCache(2)
+	cache.Put("a", "1")
+	cache.Put("b", "2")
+
+	if val, ok := cache.Peek("a"); !ok || val != "1" {
+		t.Errorf("Peek(a) = (%q, %v), want (1, true)", val, ok)
+	}
+	// Peek не робить "a" нещодавно використаним, тож витісняється саме він
+	cache.Put("c", "3")
+	if _, ok := cache.Peek("a"); ok {
+		t.Errorf("Expected a to be evicted")
+	}
+}
+
+func Test_lruCacheImpl_Delete(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		want     bool
+		wantKeys []string
+	}{
+		{
+			name:     "Existing key",
+			key:      "a",
+			want:     true,
+			wantKeys: []string{"b"},
+		},
+		{
+			name:     "Non-existing key",
+			key:      "c",
+			want:     false,
+			wantKeys: []string{"b", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewLruCache(2)
+			cache.Put("a", "1")
+			cache.Put("b", "2")
+			if got := cache.Delete(tt.key); got != tt.want {
+				t.Errorf("Delete(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+			if got := cache.Keys(); !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("Keys() = %v, want %v", got, tt.wantKeys)
+			}
+			if cache.Len() != len(tt.wantKeys) {
+				t.Errorf("Len() = %d, want %d", cache.Len(), len(tt.wantKeys))
+			}
+		})
+	}
+}
+
+func Test_lruCacheImpl_KeysAndPurge(t *testing.T) {
+	cache := NewLruCache(3)
+	cache.Put("a", "1")
+	cache.Put("b", "2")
+	cache.Put("c", "3")
+	cache.Get("a")
+
+	if got, want := cache.Keys(), []string{"a", "c", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 || len(cache.Keys()) != 0 {
+		t.Errorf("Expected empty cache after Purge, got %v", cache.Keys())
+	}
+	cache.Put("d", "4")
+	if val, ok := cache.Get("d"); !ok || val != "4" {
+		t.Errorf("Expected d after Purge, got (%v, %v)", val, ok)
+	}
+}
+
+// setClock підміняє годинник кешу на час тесту; час просувається через повернений вказівник
+func setClock(t *testing.T, start time.Time) *time.Time {
+	t.Helper()
+	now := start
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+	return &now
+}
+
+type evictRecord struct {
+	key    string
+	value  string
+	reason EvictReason
+}
+
+func Test_lruCacheImpl_TTL(t *testing.T) {
+	now := setClock(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var evicted []evictRecord
+	cache := NewWithOptions(3, Options[string, string]{
+		TTL: time.Minute,
+		OnEvict: func(key, value string, reason EvictReason) {
+			evicted = append(evicted, evictRecord{key, value, reason})
+		},
+	})
+	cache.Put("a", "1")
+	cache.PutWithTTL("b", "2", time.Hour)
+	cache.PutWithTTL("c", "3", 0)
+
+	*now = now.Add(time.Minute)
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("Expected a to be expired")
+	}
+	if got, want := cache.Keys(), []string{"c", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+
+	*now = now.Add(time.Hour)
+	if _, ok := cache.Peek("b"); ok {
+		t.Errorf("Expected b to be expired")
+	}
+	if val, ok := cache.Get("c"); !ok || val != "3" {
+		t.Errorf("Expected c without expiry, got (%v, %v)", val, ok)
+	}
+
+	want := []evictRecord{{"a", "1", EvictExpired}, {"b", "2", EvictExpired}}
+	if !reflect.DeepEqual(evicted, want) {
+		t.Errorf("evicted = %v, want %v", evicted, want)
+	}
+}
+
+func Test_lruCacheImpl_OnEvict(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(c LRUCache[string, string], now *time.Time)
+		want  []evictRecord
+	}{
+		{
+			name:  "Capacity",
+			write: func(c LRUCache[string, string], now *time.Time) { c.Put("c", "3") },
+			want:  []evictRecord{{"a", "1", EvictCapacity}},
+		},
+		{
+			name:  "Replaced",
+			write: func(c LRUCache[string, string], now *time.Time) { c.Put("a", "10") },
+			want:  []evictRecord{{"a", "1", EvictReplaced}},
+		},
+		{
+			name:  "Deleted",
+			write: func(c LRUCache[string, string], now *time.Time) { c.Delete("b"); c.Delete("x") },
+			want:  []evictRecord{{"b", "2", EvictDeleted}},
+		},
+		{
+			name:  "Purge",
+			write: func(c LRUCache[string, string], now *time.Time) { c.Purge() },
+			want:  []evictRecord{{"b", "2", EvictDeleted}, {"a", "1", EvictDeleted}},
+		},
+		{
+			name: "DeleteExpired",
+			write: func(c LRUCache[string, string], now *time.Time) {
+				c.PutWithTTL("b", "20", time.Second)
+				*now = now.Add(time.Second)
+				if removed := c.DeleteExpired(); removed != 1 {
+					t.Errorf("DeleteExpired() = %d, want 1", removed)
+				}
+			},
+			want: []evictRecord{{"b", "2", EvictReplaced}, {"b", "20", EvictExpired}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := setClock(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			var evicted []evictRecord
+			cache := NewWithOptions(2, Options[string, string]{
+				OnEvict: func(key, value string, reason EvictReason) {
+					evicted = append(evicted, evictRecord{key, value, reason})
+				},
+			})
+			cache.Put("a", "1")
+			cache.Put("b", "2")
+			tt.write(cache, now)
+			if !reflect.DeepEqual(evicted, tt.want) {
+				t.Errorf("evicted = %v, want %v", evicted, tt.want)
+			}
+		})
+	}
+}
+
+func Test_lruCacheImpl_Stats(t *testing.T) {
+	now := setClock(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := New[string, int](2)
+	cache.Put("a", 1)
+	cache.Put("a", 2)
+	cache.PutWithTTL("b", 3, time.Minute)
+	cache.Get("a")
+	cache.Get("x")
+	cache.Peek("a")
+	cache.Put("c", 4) // витісняє b
+	*now = now.Add(time.Minute)
+	cache.PutWithTTL("d", 5, time.Second)
+	*now = now.Add(time.Second)
+	cache.Get("d")
+
+	want := Stats{Hits: 1, Misses: 2, Insertions: 4, Evictions: 2, Expirations: 1}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_lruCacheImpl_Resize(t *testing.T) {
+	tests := []struct {
+		name        string
+		capacity    int
+		newCapacity int
+		wantEvicted int
+		wantKeys    []string // після Resize додаються f і g
+	}{
+		{
+			name:        "Shrink",
+			capacity:    4,
+			newCapacity: 2,
+			wantEvicted: 2,
+			wantKeys:    []string{"g", "f"},
+		},
+		{
+			name:        "Grow",
+			capacity:    2,
+			newCapacity: 3,
+			wantEvicted: 0,
+			wantKeys:    []string{"g", "f", "e"},
+		},
+		{
+			name:        "Unbounded",
+			capacity:    1,
+			newCapacity: 0,
+			wantEvicted: 0,
+			wantKeys:    []string{"g", "f", "e"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewLruCache(tt.capacity)
+			for _, key := range []string{"a", "b", "c", "d", "e"} {
+				cache.Put(key, key)
+			}
+			if got := cache.Resize(tt.newCapacity); got != tt.wantEvicted {
+				t.Errorf("Resize(%d) = %d, want %d", tt.newCapacity, got, tt.wantEvicted)
+			}
+			cache.Put("f", "f")
+			cache.Put("g", "g")
+			if got := cache.Keys(); !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("Keys() = %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func Test_lruCacheImpl_Unbounded(t *testing.T) {
+	for _, capacity := range []int{0, -1} {
+		cache := New[int, int](capacity)
+		for i := range 100 {
+			cache.Put(i, i)
+		}
+		if cache.Len() != 100 || cache.Stats().Evictions != 0 {
+			t.Errorf("capacity %d: Len() = %d, Evictions = %d, want 100 and 0", capacity, cache.Len(), cache.Stats().Evictions)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidInterval = errors.New("janitor interval must be positive")
+
+// StartJanitor запускає фонове видалення прострочених записів кожні interval
+// і зупиняє його, коли ctx скасовано. Кеш має бути потокобезпечним
+// (NewSynced або NewSharded). Якщо interval <= 0, janitor не запускається
+// і повертається ErrInvalidInterval.
+func StartJanitor[K comparable, V any](ctx context.Context, cache LRUCache[K, V], interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: got %s", ErrInvalidInterval, interval)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cache.DeleteExpired()
+			}
+		}
+	}()
+	return nil
+}
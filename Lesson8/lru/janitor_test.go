@@ -0,0 +1,42 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartJanitor(t *testing.T) {
+	var expired atomic.Int32
+	cache := NewShardedWithOptions(10, 2, Options[string, int]{
+		TTL: 10 * time.Millisecond,
+		OnEvict: func(key string, value int, reason EvictReason) {
+			if reason == EvictExpired {
+				expired.Add(1)
+			}
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := StartJanitor(ctx, cache, interval); !errors.Is(err, ErrInvalidInterval) {
+			t.Errorf("StartJanitor(%s) error = %v, wantErr %v", interval, err, ErrInvalidInterval)
+		}
+	}
+	if err := StartJanitor(ctx, cache, 5*time.Millisecond); err != nil {
+		t.Fatalf("StartJanitor() error = %v", err)
+	}
+
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.PutWithTTL("c", 3, 0)
+	deadline := time.Now().Add(time.Second)
+	for cache.Len() > 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if cache.Len() != 1 || expired.Load() != 2 {
+		t.Errorf("Len() = %d, expired = %d, want 1 and 2", cache.Len(), expired.Load())
+	}
+}
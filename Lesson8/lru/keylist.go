@@ -0,0 +1,56 @@
+package lru
+
+import "container/list"
+
+// keyList - впорядкований набір ключів з O(1) пошуком: map для пошуку і
+// двозв'язний список, де на початку найновіші ключі. Основа черг 2Q та списків ARC.
+type keyList[K comparable] struct {
+	items map[K]*list.Element
+	order *list.List
+}
+
+func newKeyList[K comparable]() *keyList[K] {
+	return &keyList[K]{items: make(map[K]*list.Element), order: list.New()}
+}
+
+func (l *keyList[K]) Len() int {
+	return l.order.Len()
+}
+
+func (l *keyList[K]) Contains(key K) bool {
+	_, ok := l.items[key]
+	return ok
+}
+
+func (l *keyList[K]) PushFront(key K) {
+	l.items[key] = l.order.PushFront(key)
+}
+
+func (l *keyList[K]) MoveToFront(key K) {
+	if el, ok := l.items[key]; ok {
+		l.order.MoveToFront(el)
+	}
+}
+
+func (l *keyList[K]) Remove(key K) bool {
+	el, ok := l.items[key]
+	if !ok {
+		return false
+	}
+	l.order.Remove(el)
+	delete(l.items, key)
+	return true
+}
+
+// RemoveBack видаляє і повертає найстаріший ключ
+func (l *keyList[K]) RemoveBack() (K, bool) {
+	el := l.order.Back()
+	if el == nil {
+		var zero K
+		return zero, false
+	}
+	key := el.Value.(K)
+	l.order.Remove(el)
+	delete(l.items, key)
+	return key, true
+}
@@ -0,0 +1,123 @@
+package lru
+
+import "container/list"
+
+// lfuCache витісняє найрідше використаний запис; серед записів з однаковою
+// частотою - найдавніше використаний. Усі операції O(1): записи згруповані
+// у списки за частотою. Не потокобезпечний.
+type lfuCache[K comparable, V any] struct {
+	capacity int
+	items    map[K]*list.Element
+	freqs    map[int]*list.List
+	minFreq  int
+}
+
+type lfuEntry[K comparable, V any] struct {
+	key   K
+	value V
+	freq  int
+}
+
+// NewLFU створює LFU-кеш місткістю capacity; capacity <= 0 - без обмеження
+func NewLFU[K comparable, V any](capacity int) Cache[K, V] {
+	return &lfuCache[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		freqs:    make(map[int]*list.List),
+	}
+}
+
+func (c *lfuCache[K, V]) Put(key K, value V) {
+	if el, exists := c.items[key]; exists {
+		el.Value.(*lfuEntry[K, V]).value = value
+		c.increment(el)
+		return
+	}
+	if c.capacity > 0 && len(c.items) >= c.capacity {
+		c.evict()
+	}
+	c.items[key] = c.bucket(1).PushFront(&lfuEntry[K, V]{key: key, value: value, freq: 1})
+	c.minFreq = 1
+}
+
+func (c *lfuCache[K, V]) Get(key K) (V, bool) {
+	el, exists := c.items[key]
+	if !exists {
+		var zero V
+		return zero, false
+	}
+	c.increment(el)
+	return el.Value.(*lfuEntry[K, V]).value, true
+}
+
+func (c *lfuCache[K, V]) Peek(key K) (V, bool) {
+	if el, exists := c.items[key]; exists {
+		return el.Value.(*lfuEntry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (c *lfuCache[K, V]) Delete(key K) bool {
+	el, exists := c.items[key]
+	if !exists {
+		return false
+	}
+	c.unlink(el)
+	delete(c.items, key)
+	return true
+}
+
+func (c *lfuCache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// increment переносить запис у список наступної частоти
+func (c *lfuCache[K, V]) increment(el *list.Element) {
+	e := el.Value.(*lfuEntry[K, V])
+	c.unlink(el)
+	if e.freq == c.minFreq && c.freqs[e.freq] == nil {
+		c.minFreq++
+	}
+	e.freq++
+	c.items[e.key] = c.bucket(e.freq).PushFront(e)
+}
+
+func (c *lfuCache[K, V]) evict() {
+	bucket := c.freqs[c.minFreq]
+	if bucket == nil {
+		// Delete міг спорожнити список мінімальної частоти - шукаємо наступну
+		if len(c.freqs) == 0 {
+			return
+		}
+		c.minFreq = 0
+		for freq := range c.freqs {
+			if c.minFreq == 0 || freq < c.minFreq {
+				c.minFreq = freq
+			}
+		}
+		bucket = c.freqs[c.minFreq]
+	}
+	el := bucket.Back()
+	c.unlink(el)
+	delete(c.items, el.Value.(*lfuEntry[K, V]).key)
+}
+
+// unlink прибирає запис зі списку його частоти; порожні списки видаляються
+func (c *lfuCache[K, V]) unlink(el *list.Element) {
+	freq := el.Value.(*lfuEntry[K, V]).freq
+	bucket := c.freqs[freq]
+	bucket.Remove(el)
+	if bucket.Len() == 0 {
+		delete(c.freqs, freq)
+	}
+}
+
+func (c *lfuCache[K, V]) bucket(freq int) *list.List {
+	bucket, ok := c.freqs[freq]
+	if !ok {
+		bucket = list.New()
+		c.freqs[freq] = bucket
+	}
+	return bucket
+}
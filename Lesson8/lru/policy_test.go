@@ -0,0 +1,293 @@
+package lru
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var policies = []struct {
+	name string
+	new  func(capacity int) Cache[int, int]
+}{
+	{name: "LRU", new: func(capacity int) Cache[int, int] { return New[int, int](capacity) }},
+	{name: "LFU", new: NewLFU[int, int]},
+	{name: "2Q", new: New2Q[int, int]},
+	{name: "ARC", new: NewARC[int, int]},
+}
+
+func TestCache_Policies(t *testing.T) {
+	for _, p := range policies {
+		t.Run(p.name, func(t *testing.T) {
+			cache := p.new(2)
+			cache.Put(1, 10)
+			cache.Put(1, 11)
+			if val, ok := cache.Get(1); !ok || val != 11 {
+				t.Errorf("Get(1) = (%v, %v), want (11, true)", val, ok)
+			}
+			if val, ok := cache.Peek(1); !ok || val != 11 {
+				t.Errorf("Peek(1) = (%v, %v), want (11, true)", val, ok)
+			}
+			if _, ok := cache.Get(2); ok {
+				t.Errorf("Get(2) found missing key")
+			}
+			if !cache.Delete(1) || cache.Delete(1) || cache.Len() != 0 {
+				t.Errorf("Expected Delete(1) to succeed once and empty the cache")
+			}
+		})
+	}
+}
+
+// Випадкові операції не повинні порушувати місткість і внутрішні інваріанти
+func TestCache_PoliciesCapacity(t *testing.T) {
+	for _, p := range policies {
+		t.Run(p.name, func(t *testing.T) {
+			const capacity = 16
+			cache := p.new(capacity)
+			rng := rand.New(rand.NewPCG(1, 2))
+			for i := range 20000 {
+				key := rng.IntN(64)
+				switch rng.IntN(10) {
+				case 0:
+					cache.Delete(key)
+				case 1, 2, 3:
+					cache.Put(key, i)
+				default:
+					cache.Get(key)
+				}
+				if cache.Len() > capacity {
+					t.Fatalf("step %d: Len() = %d, exceeds capacity %d", i, cache.Len(), capacity)
+				}
+				if arc, ok := cache.(*arcCache[int, int]); ok {
+					tracked := arc.recent.Len() + arc.frequent.Len() + arc.recentGhost.Len() + arc.frequentGhost.Len()
+					if arc.recent.Len()+arc.frequent.Len() != arc.Len() || tracked > 2*capacity {
+						t.Fatalf("step %d: ARC lists are inconsistent: %d resident, %d tracked", i, arc.Len(), tracked)
+					}
+				}
+			}
+
+			unbounded := p.new(0)
+			for i := range 1000 {
+				unbounded.Put(i, i)
+				unbounded.Get(i)
+			}
+			if unbounded.Len() != 1000 {
+				t.Errorf("unbounded Len() = %d, want 1000", unbounded.Len())
+			}
+		})
+	}
+}
+
+func TestNewLFU_EvictsLeastFrequent(t *testing.T) {
+	cache := NewLFU[string, int](2)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("b")
+	cache.Put("c", 3) // b використано рідше за a
+
+	if _, ok := cache.Peek("b"); ok {
+		t.Errorf("Expected b to be evicted")
+	}
+	cache.Delete("c")
+	cache.Put("d", 4)
+	cache.Put("e", 5) // після Delete найменша частота - у d
+	if _, ok := cache.Peek("a"); !ok {
+		t.Errorf("Expected a to stay")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+}
+
+// Робочий набір, запитаний двічі, переживає скан, більший за місткість кешу
+func TestCache_ScanResistance(t *testing.T) {
+	tests := []struct {
+		name      string
+		new       func(capacity int) Cache[int, int]
+		wantSaved bool
+	}{
+		{name: "LRU", new: func(capacity int) Cache[int, int] { return New[int, int](capacity) }, wantSaved: false},
+		{name: "LFU", new: NewLFU[int, int], wantSaved: true},
+		{name: "2Q", new: New2Q[int, int], wantSaved: true},
+		{name: "ARC", new: NewARC[int, int], wantSaved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := tt.new(20)
+			for range 2 {
+				for key := range 10 {
+					if _, ok := cache.Get(key); !ok {
+						cache.Put(key, key)
+					}
+				}
+			}
+			for key := 1000; key < 1100; key++ {
+				if _, ok := cache.Get(key); !ok {
+					cache.Put(key, key)
+				}
+			}
+			saved := 0
+			for key := range 10 {
+				if _, ok := cache.Peek(key); ok {
+					saved++
+				}
+			}
+			if got := saved == 10; got != tt.wantSaved {
+				t.Errorf("working set kept %d of 10 keys, want all kept = %v", saved, tt.wantSaved)
+			}
+		})
+	}
+}
+
+// generateTrace відтворювано генерує послідовність ключів: гарячий робочий набір
+// (розподіл Ципфа) вперемішку з одноразовими сканами
+func generateTrace(seed uint64, length, hotKeys, scanEvery, scanLength int) []int {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	zipf := rand.NewZipf(rng, 1.1, 1, uint64(hotKeys-1))
+	trace := make([]int, 0, length)
+	nextScanKey := hotKeys
+	for len(trace) < length {
+		if scanEvery > 0 && len(trace)%scanEvery == 0 {
+			for range scanLength {
+				trace = append(trace, nextScanKey)
+				nextScanKey++
+			}
+		}
+		trace = append(trace, int(zipf.Uint64()))
+	}
+	return trace[:length]
+}
+
+type namedTrace struct {
+	name  string
+	trace []int
+}
+
+// loadTrace читає записану трасу: один ключ на рядок, порожні рядки та
+// рядки з # пропускаються. Ключі нумеруються в порядку першої появи.
+func loadTrace(path string) ([]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ids := make(map[string]int)
+	var trace []int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		id, ok := ids[key]
+		if !ok {
+			id = len(ids)
+			ids[key] = id
+		}
+		trace = append(trace, id)
+	}
+	return trace, scanner.Err()
+}
+
+// loadTraces повертає записані траси з testdata/*.trace (якщо їх туди покладено)
+// і синтетичні траси generateTrace з фіксованим зерном
+func loadTraces(tb testing.TB) []namedTrace {
+	tb.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.trace"))
+	if err != nil {
+		tb.Fatalf("Glob() error = %v", err)
+	}
+	var traces []namedTrace
+	for _, path := range paths {
+		trace, err := loadTrace(path)
+		if err != nil {
+			tb.Fatalf("loadTrace(%s) error = %v", path, err)
+		}
+		if len(trace) == 0 {
+			tb.Fatalf("loadTrace(%s) returned empty trace", path)
+		}
+		traces = append(traces, namedTrace{name: strings.TrimSuffix(filepath.Base(path), ".trace"), trace: trace})
+	}
+	return append(traces,
+		namedTrace{name: "zipf", trace: generateTrace(1, 100000, 5000, 0, 0)},
+		namedTrace{name: "zipf+scans", trace: generateTrace(2, 100000, 5000, 2000, 1000)},
+	)
+}
+
+// replay відтворює трасу як кеш перед повільним сховищем: промах - Get, потім Put
+func replay(cache Cache[int, int], trace []int) float64 {
+	hits := 0
+	for _, key := range trace {
+		if _, ok := cache.Get(key); ok {
+			hits++
+			continue
+		}
+		cache.Put(key, key)
+	}
+	return float64(hits) / float64(len(trace))
+}
+
+func TestLoadTrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "small.trace")
+	data := "# коментар\n/a\n/b\n\n/a\n  /c  \n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	trace, err := loadTrace(path)
+	if err != nil {
+		t.Fatalf("loadTrace() error = %v", err)
+	}
+	if want := []int{0, 1, 0, 2}; !slices.Equal(trace, want) {
+		t.Errorf("loadTrace() = %v, want %v", trace, want)
+	}
+	if _, err := loadTrace(filepath.Join(t.TempDir(), "missing.trace")); err == nil {
+		t.Errorf("loadTrace() expected error for missing file")
+	}
+}
+
+func TestTraceReplay_ScansFavorAdaptivePolicies(t *testing.T) {
+	for _, tr := range loadTraces(t) {
+		if tr.name == "zipf" {
+			continue // без сканів LRU не поступається іншим політикам
+		}
+		t.Run(tr.name, func(t *testing.T) {
+			ratios := make(map[string]float64)
+			for _, p := range policies {
+				ratios[p.name] = replay(p.new(500), tr.trace)
+			}
+			for _, name := range []string{"2Q", "ARC"} {
+				if ratios[name] <= ratios["LRU"] {
+					t.Errorf("%s hit ratio %.3f, want above LRU %.3f", name, ratios[name], ratios["LRU"])
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkTraceReplay порівнює частку влучань (метрика hit%) політик на трасах
+// з testdata/*.trace та синтетичних:
+//
+//	go test -run xxx -bench TraceReplay ./lru
+func BenchmarkTraceReplay(b *testing.B) {
+	for _, tr := range loadTraces(b) {
+		for _, capacity := range []int{100, 500} {
+			for _, p := range policies {
+				b.Run(fmt.Sprintf("%s/%d/%s", tr.name, capacity, p.name), func(b *testing.B) {
+					var ratio float64
+					for b.Loop() {
+						ratio = replay(p.new(capacity), tr.trace)
+					}
+					b.ReportMetric(100*ratio, "hit%")
+				})
+			}
+		}
+	}
+}
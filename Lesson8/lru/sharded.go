@@ -0,0 +1,206 @@
+package lru
+
+import (
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// syncedCache - кеш під одним м'ютексом. Навіть Get змінює порядок
+// використання, тому блокування завжди ексклюзивне.
+type syncedCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	cache LRUCache[K, V]
+}
+
+// NewSynced створює потокобезпечний кеш місткістю capacity з одним блокуванням
+func NewSynced[K comparable, V any](capacity int) LRUCache[K, V] {
+	return NewSyncedWithOptions(capacity, Options[K, V]{})
+}
+
+// NewSyncedWithOptions - NewSynced з терміном дії та OnEvict
+func NewSyncedWithOptions[K comparable, V any](capacity int, options Options[K, V]) LRUCache[K, V] {
+	return &syncedCache[K, V]{cache: NewWithOptions(capacity, options)}
+}
+
+func (c *syncedCache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Put(key, value)
+}
+
+func (c *syncedCache[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.PutWithTTL(key, value, ttl)
+}
+
+func (c *syncedCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Get(key)
+}
+
+func (c *syncedCache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Peek(key)
+}
+
+func (c *syncedCache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Delete(key)
+}
+
+func (c *syncedCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Len()
+}
+
+func (c *syncedCache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Keys()
+}
+
+func (c *syncedCache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Purge()
+}
+
+func (c *syncedCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Stats()
+}
+
+func (c *syncedCache[K, V]) Resize(capacity int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Resize(capacity)
+}
+
+func (c *syncedCache[K, V]) DeleteExpired() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.DeleteExpired()
+}
+
+// shardedCache розподіляє ключі між незалежними кешами з власними блокуваннями.
+// LRU-порядок діє в межах шарда, тож витісняється найдавніший ключ свого шарда,
+// а не всього кешу.
+type shardedCache[K comparable, V any] struct {
+	seed   maphash.Seed
+	shards []*syncedCache[K, V]
+}
+
+// NewSharded створює потокобезпечний кеш із shards шардами. Місткість ділиться
+// між шардами з округленням угору, тож загальна місткість наближена: не менше
+// capacity і не більше capacity+shards-1. Шардів не буває більше, ніж capacity;
+// capacity <= 0 - кеш без обмеження розміру.
+func NewSharded[K comparable, V any](capacity, shards int) LRUCache[K, V] {
+	return NewShardedWithOptions(capacity, shards, Options[K, V]{})
+}
+
+// NewShardedWithOptions - NewSharded з терміном дії та OnEvict
+func NewShardedWithOptions[K comparable, V any](capacity, shards int, options Options[K, V]) LRUCache[K, V] {
+	if capacity > 0 {
+		shards = min(shards, capacity)
+	}
+	c := &shardedCache[K, V]{seed: maphash.MakeSeed(), shards: make([]*syncedCache[K, V], max(1, shards))}
+	for i := range c.shards {
+		c.shards[i] = &syncedCache[K, V]{cache: NewWithOptions(c.shardCapacity(capacity), options)}
+	}
+	return c
+}
+
+// shardCapacity ділить загальну місткість між шардами з округленням угору
+func (c *shardedCache[K, V]) shardCapacity(capacity int) int {
+	if capacity <= 0 {
+		return 0
+	}
+	n := len(c.shards)
+	return (capacity + n - 1) / n
+}
+
+func (c *shardedCache[K, V]) shard(key K) *syncedCache[K, V] {
+	return c.shards[maphash.Comparable(c.seed, key)%uint64(len(c.shards))]
+}
+
+func (c *shardedCache[K, V]) Put(key K, value V) {
+	c.shard(key).Put(key, value)
+}
+
+func (c *shardedCache[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
+	c.shard(key).PutWithTTL(key, value, ttl)
+}
+
+func (c *shardedCache[K, V]) Get(key K) (V, bool) {
+	return c.shard(key).Get(key)
+}
+
+func (c *shardedCache[K, V]) Peek(key K) (V, bool) {
+	return c.shard(key).Peek(key)
+}
+
+func (c *shardedCache[K, V]) Delete(key K) bool {
+	return c.shard(key).Delete(key)
+}
+
+func (c *shardedCache[K, V]) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+	return n
+}
+
+// Keys повертає ключі шард за шардом; порядок використання зберігається лише
+// всередині шарда
+func (c *shardedCache[K, V]) Keys() []K {
+	var keys []K
+	for _, s := range c.shards {
+		keys = append(keys, s.Keys()...)
+	}
+	return keys
+}
+
+func (c *shardedCache[K, V]) Purge() {
+	for _, s := range c.shards {
+		s.Purge()
+	}
+}
+
+func (c *shardedCache[K, V]) DeleteExpired() int {
+	removed := 0
+	for _, s := range c.shards {
+		removed += s.DeleteExpired()
+	}
+	return removed
+}
+
+func (c *shardedCache[K, V]) Stats() Stats {
+	var total Stats
+	for _, s := range c.shards {
+		st := s.Stats()
+		total.Hits += st.Hits
+		total.Misses += st.Misses
+		total.Insertions += st.Insertions
+		total.Evictions += st.Evictions
+		total.Expirations += st.Expirations
+	}
+	return total
+}
+
+// Resize ділить нову місткість між шардами, кількість шардів не змінюється.
+// Якщо capacity менша за кількість шардів, у кожному лишається щонайменше один запис.
+func (c *shardedCache[K, V]) Resize(capacity int) int {
+	evicted := 0
+	for _, s := range c.shards {
+		evicted += s.Resize(c.shardCapacity(capacity))
+	}
+	return evicted
+}
@@ -0,0 +1,148 @@
+package lru
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestNewSharded(t *testing.T) {
+	tests := []struct {
+		name       string
+		capacity   int
+		shards     int
+		wantShards int
+		wantMax    int
+	}{
+		{name: "Capacity split evenly", capacity: 64, shards: 8, wantShards: 8, wantMax: 64},
+		{name: "Capacity rounded up", capacity: 10, shards: 4, wantShards: 4, wantMax: 12},
+		{name: "More shards than capacity", capacity: 2, shards: 16, wantShards: 2, wantMax: 2},
+		{name: "Non-positive shards", capacity: 5, shards: 0, wantShards: 1, wantMax: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewSharded[int, int](tt.capacity, tt.shards)
+			if got := len(cache.(*shardedCache[int, int]).shards); got != tt.wantShards {
+				t.Errorf("shards = %d, want %d", got, tt.wantShards)
+			}
+			for i := range 10 * tt.capacity {
+				cache.Put(i, i)
+			}
+			if got := cache.Len(); got < tt.capacity || got > tt.wantMax {
+				t.Errorf("Len() = %d, want between %d and %d", got, tt.capacity, tt.wantMax)
+			}
+		})
+	}
+}
+
+func Test_shardedCache_Operations(t *testing.T) {
+	cache := NewSharded[string, string](8, 4)
+	cache.Put("a", "1")
+	cache.Put("b", "2")
+
+	if val, ok := cache.Get("a"); !ok || val != "1" {
+		t.Errorf("Get(a) = (%q, %v), want (1, true)", val, ok)
+	}
+	if val, ok := cache.Peek("b"); !ok || val != "2" {
+		t.Errorf("Peek(b) = (%q, %v), want (2, true)", val, ok)
+	}
+	if !cache.Delete("a") || cache.Delete("a") {
+		t.Errorf("Expected Delete(a) to succeed once")
+	}
+	if got := cache.Keys(); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Keys() = %v, want [b]", got)
+	}
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("Len() after Purge = %d, want 0", cache.Len())
+	}
+}
+
+func Test_shardedCache_StatsAndResize(t *testing.T) {
+	cache := NewSharded[int, int](64, 4)
+	for i := range 64 {
+		cache.Put(i, i)
+	}
+	for i := range 64 {
+		cache.Get(i)
+	}
+	// Шарди заповнюються нерівномірно, тож частина ключів уже витіснена
+	before := cache.Len()
+	evicted := cache.Resize(8)
+	if cache.Len() > 8 || evicted != before-cache.Len() {
+		t.Errorf("Resize(8) = %d, Len() = %d -> %d, want at most 8 entries left", evicted, before, cache.Len())
+	}
+	st := cache.Stats()
+	if st.Insertions != 64 || st.Hits+st.Misses != 64 || st.Evictions != uint64(64-cache.Len()) {
+		t.Errorf("Stats() = %+v, want 64 insertions, 64 lookups and %d evictions", st, 64-cache.Len())
+	}
+}
+
+// Запускати з -race: конкурентні Get і Put не повинні пошкоджувати порядок
+func TestConcurrentAccess(t *testing.T) {
+	caches := map[string]LRUCache[string, int]{
+		"Synced":  NewSynced[string, int](100),
+		"Sharded": NewSharded[string, int](100, 8),
+	}
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			for g := range 8 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range 1000 {
+						key := strconv.Itoa((g*1000 + i) % 300)
+						cache.Put(key, i)
+						cache.Get(key)
+						if i%10 == 0 {
+							cache.Delete(key)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			if got := cache.Len(); got > 100+7 {
+				t.Errorf("Len() = %d, exceeds capacity", got)
+			}
+			if got := len(cache.Keys()); got != cache.Len() {
+				t.Errorf("len(Keys()) = %d, Len() = %d", got, cache.Len())
+			}
+		})
+	}
+}
+
+// Навантаження гарячого шляху: 90% Get, 10% Put по 4096 ключах у кеші на 1024
+func benchmarkCache(b *testing.B, cache LRUCache[string, int]) {
+	keys := make([]string, 4096)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		cache.Put(keys[i], i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.IntN(len(keys)) // горутини не йдуть по ключах синхронно
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			if i%10 == 0 {
+				cache.Put(key, i)
+			} else {
+				cache.Get(key)
+			}
+			i += 7
+		}
+	})
+}
+
+func BenchmarkSynced(b *testing.B) {
+	benchmarkCache(b, NewSynced[string, int](1024))
+}
+
+func BenchmarkSharded(b *testing.B) {
+	for _, shards := range []int{4, 16, 64} {
+		b.Run(strconv.Itoa(shards), func(b *testing.B) {
+			benchmarkCache(b, NewSharded[string, int](1024, shards))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package lru
+
+// twoQueueCache - 2Q (Johnson, Shasha). Новий ключ потрапляє у FIFO-чергу recent;
+// витіснений з неї ключ запам'ятовується в recentGhost без значення. Повторний
+// запит ключа з recent або recentGhost переносить його в LRU-чергу frequent, тож
+// одноразові скани проходять через recent і не витісняють робочий набір.
+// На відміну від класичного 2Q, де влучання в recent (A1in) не змінює черг,
+// тут воно одразу підвищує ключ у frequent: короткі повтори швидше
+// потрапляють у робочий набір ціною чутливості до щільних сканів.
+// Не потокобезпечний.
+type twoQueueCache[K comparable, V any] struct {
+	capacity    int
+	recentSize  int // місткість recent, ~25% capacity
+	ghostSize   int // місткість ghost, ~50% capacity
+	values      map[K]V
+	recent      *keyList[K]
+	frequent    *keyList[K]
+	recentGhost *keyList[K]
+}
+
+// New2Q створює 2Q-кеш місткістю capacity; capacity <= 0 - без обмеження
+func New2Q[K comparable, V any](capacity int) Cache[K, V] {
+	return &twoQueueCache[K, V]{
+		capacity:    capacity,
+		recentSize:  max(1, capacity/4),
+		ghostSize:   max(1, capacity/2),
+		values:      make(map[K]V),
+		recent:      newKeyList[K](),
+		frequent:    newKeyList[K](),
+		recentGhost: newKeyList[K](),
+	}
+}
+
+func (c *twoQueueCache[K, V]) Put(key K, value V) {
+	if _, exists := c.values[key]; exists {
+		c.values[key] = value
+		c.promote(key)
+		return
+	}
+	seen := c.recentGhost.Remove(key)
+	c.reclaim()
+	if seen {
+		c.frequent.PushFront(key)
+	} else {
+		c.recent.PushFront(key)
+	}
+	c.values[key] = value
+}
+
+func (c *twoQueueCache[K, V]) Get(key K) (V, bool) {
+	value, exists := c.values[key]
+	if exists {
+		c.promote(key)
+	}
+	return value, exists
+}
+
+func (c *twoQueueCache[K, V]) Peek(key K) (V, bool) {
+	value, exists := c.values[key]
+	return value, exists
+}
+
+func (c *twoQueueCache[K, V]) Delete(key K) bool {
+	if _, exists := c.values[key]; !exists {
+		return false
+	}
+	delete(c.values, key)
+	if !c.recent.Remove(key) {
+		c.frequent.Remove(key)
+	}
+	return true
+}
+
+func (c *twoQueueCache[K, V]) Len() int {
+	return len(c.values)
+}
+
+// promote робить резидентний ключ найсвіжішим у frequent
+func (c *twoQueueCache[K, V]) promote(key K) {
+	if c.recent.Remove(key) {
+		c.frequent.PushFront(key)
+		return
+	}
+	c.frequent.MoveToFront(key)
+}
+
+// reclaim звільняє місце для нового ключа: спершу з recent (з пам'яттю в ghost),
+// якщо вона переповнена, інакше з frequent
+func (c *twoQueueCache[K, V]) reclaim() {
+	if c.capacity <= 0 || len(c.values) < c.capacity {
+		return
+	}
+	if c.recent.Len() > c.recentSize || c.frequent.Len() == 0 {
+		if key, ok := c.recent.RemoveBack(); ok {
+			delete(c.values, key)
+			c.recentGhost.PushFront(key)
+			if c.recentGhost.Len() > c.ghostSize {
+				c.recentGhost.RemoveBack()
+			}
+			return
+		}
+	}
+	if key, ok := c.frequent.RemoveBack(); ok {
+		delete(c.values, key)
+	}
+}